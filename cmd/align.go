@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// alignCmd represents the align command
+var alignCmd = &cobra.Command{
+	Use:   "align",
+	Short: "Find activities siblings can do at the same time and place",
+	Long: `Find combinations of one activity per person at the same center on the same
+day, starting within --window of each other, among the activities loaded for
+them. The combinations with the least total wait come first: the time each
+person spends at the center outside of their own activity, added up.
+
+  gojoin align --person ana --person ben --window 45m`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		persons, err := cmd.Flags().GetStringArray("person")
+		if err != nil {
+			return err
+		}
+
+		window, err := cmd.Flags().GetDuration("window")
+		if err != nil {
+			return err
+		}
+		if window < 0 {
+			return usageErrorf("--window must not be negative")
+		}
+
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.AgendaText, internal.FormatJSON, internal.FormatCSV}, format) {
+			return usageErrorf("unexpected format %v, expected text, json or csv", format)
+		}
+
+		plan, err := internal.ReadPlan(inputPath)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range persons {
+			if !slices.Contains(plan.Persons(), p) {
+				return usageErrorf("unknown person %v, the plan has: %v", p, plan.Persons())
+			}
+		}
+
+		alignments, err := models.FindAlignments(plan, persons, window)
+		if err != nil {
+			return usageErr(err)
+		}
+		if limit > 0 && len(alignments) > limit {
+			alignments = alignments[:limit]
+		}
+
+		return writeAlignments(alignments, format)
+	},
+}
+
+func writeAlignments(alignments []*models.Alignment, format string) error {
+	switch format {
+	case internal.FormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(alignments)
+	case internal.FormatCSV:
+		table := internal.Table{Header: []string{"RANK", "CENTER", "DAY", "WAIT", "PERSON", "TIME", "NAME", "NUMBER", "ID"}}
+		for i, a := range alignments {
+			for _, aa := range a.Activities {
+				table.Append(strconv.Itoa(i+1), a.CenterName, a.Day, strconv.Itoa(a.WaitMinutes),
+					aa.Person, aa.Activity.TimeRange, aa.Activity.Name, aa.Activity.Number, strconv.Itoa(aa.Activity.Id))
+			}
+		}
+		return table.Write(os.Stdout, format)
+	}
+
+	if len(alignments) == 0 {
+		fmt.Println("No aligned activities found, try a wider --window")
+		return nil
+	}
+
+	for i, a := range alignments {
+		if i > 0 {
+			fmt.Println()
+		}
+		wait := "no waiting"
+		if a.Wait > 0 {
+			wait = models.FormatDuration(a.Wait) + " waiting"
+		}
+		fmt.Printf("%d. %v, %v %v, %v\n", i+1, a.CenterName, a.Day, a.TimeRange, wait)
+
+		activities := slices.Clone(a.Activities)
+		slices.SortStableFunc(activities, func(x, y *models.AlignedActivity) int {
+			xs, _ := x.Activity.StartTime()
+			ys, _ := y.Activity.StartTime()
+			return xs.Minutes() - ys.Minutes()
+		})
+		for _, aa := range activities {
+			fmt.Printf("   %-20s %-10s %s (#%s)\n", aa.Activity.TimeRange, aa.Person, aa.Activity.Name, aa.Activity.Number)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(alignCmd)
+
+	alignCmd.Flags().String("input", "output.json", "The plan file to read")
+	alignCmd.Flags().StringArray("person", nil, "A person to align, given at least twice")
+	alignCmd.MarkFlagRequired("person")
+	alignCmd.RegisterFlagCompletionFunc("person", completePersons("input"))
+	alignCmd.Flags().Duration("window", models.DefaultAlignWindow, "The most the start times of the activities may differ by")
+	alignCmd.Flags().Int("limit", 10, "The number of combinations to show, all when 0")
+	alignCmd.Flags().String("format", internal.AgendaText, "The output format: text, json or csv")
+}
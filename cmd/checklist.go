@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// checklistCmd represents the checklist command
+var checklistCmd = &cobra.Command{
+	Use:   "checklist",
+	Short: "Print the activities to register for, in priority order",
+	Long: `Print a registration-day checklist of the activities shortlisted or selected
+for the person: the number to enter for each activity, the selected ones first
+and then in plan order, with its name, time and detail link.
+
+Each activity is followed by alternates to try when it is full: the person's
+other activities of the same level that don't conflict with the rest of the
+checklist, allowing for the travel times of the config between centers, those
+on the same day and closest in time first.
+
+The html format is meant for printing: gojoin checklist --format html > checklist.html`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		person, err := cmd.Flags().GetString("person")
+		if err != nil {
+			return err
+		}
+
+		alternates, err := cmd.Flags().GetInt("alternates")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.AgendaText, internal.AgendaMarkdown, internal.AgendaHTML, internal.FormatJSON}, format) {
+			return usageErrorf("unexpected format %v, expected text, markdown, html or json", format)
+		}
+
+		plan, err := internal.ReadPlan(inputPath)
+		if err != nil {
+			return err
+		}
+
+		if persons := plan.Persons(); !slices.Contains(persons, person) {
+			return usageErrorf("unknown person %v, the plan has: %v", person, strings.Join(persons, ", "))
+		}
+
+		rules, err := config.NameRules()
+		if err != nil {
+			return err
+		}
+
+		travel, err := internal.LoadTravelTimes(config.BaseUrl(), config.Travel)
+		if err != nil {
+			return err
+		}
+
+		checklist, err := models.NewChecklist(plan, person, alternates, rules, travel)
+		if err != nil {
+			return err
+		}
+
+		if format == internal.FormatJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(checklist)
+		}
+		return internal.RenderChecklist(os.Stdout, checklist, format)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checklistCmd)
+
+	checklistCmd.Flags().String("input", "output.json", "The plan file to read")
+	checklistCmd.Flags().String("person", "", "The person to register")
+	checklistCmd.MarkFlagRequired("person")
+	checklistCmd.RegisterFlagCompletionFunc("person", completePersons("input"))
+	checklistCmd.Flags().Int("alternates", models.DefaultAlternates, "The maximum number of alternates for each activity")
+	checklistCmd.Flags().String("format", internal.AgendaText, "The output format: text, markdown, html or json")
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/spf13/cobra"
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:       "completion bash|zsh|fish|powershell",
+	Short:     "Print or install the shell completion script",
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Long: `Print the completion script for the shell, or install it with --install.
+
+Besides commands and flags, the script completes --season, --center and
+--category from the cached filters, --person from the plan file and
+--profile from the config files.
+
+--install writes the script to the user's completion directory of bash, zsh
+or fish. For zsh the directory must be in $fpath, and PowerShell users add the
+printed script to their profile:
+
+  gojoin completion powershell | Out-String | Invoke-Expression`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		install, err := cmd.Flags().GetBool("install")
+		if err != nil {
+			return err
+		}
+
+		shell := args[0]
+		if !install {
+			return writeCompletion(os.Stdout, shell)
+		}
+
+		filename, err := completionPath(shell)
+		if err != nil {
+			return usageErr(err)
+		}
+
+		err = os.MkdirAll(filepath.Dir(filename), 0775)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		err = writeCompletion(f, shell)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Installed %v completion to %v, restart the shell to use it\n", shell, filename)
+		return nil
+	},
+}
+
+func writeCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(w, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(w)
+	case "fish":
+		return rootCmd.GenFishCompletion(w, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(w)
+	}
+
+	return fmt.Errorf("unexpected shell %v", shell)
+}
+
+// completionPath returns where the shell loads the user's completion scripts
+// from.
+func completionPath(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(dataHome, "bash-completion", "completions", "gojoin"), nil
+	case "zsh":
+		return filepath.Join(home, ".zsh", "completions", "_gojoin"), nil
+	case "fish":
+		return filepath.Join(configHome, "fish", "completions", "gojoin.fish"), nil
+	}
+
+	return "", fmt.Errorf("%v completion can't be installed, add the printed script to the profile instead", shell)
+}
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeCriteria completes the IDs of the named filter list from the cache,
+// described by their names. Nothing is requested from ActiveNet.
+func completeCriteria(list string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		c, err := internal.LoadConfig()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		filters, err := internal.ReadCachedFilters(c.BaseUrl())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		criteria, err := filters.List(list)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		completions := []string{}
+		for _, c := range criteria {
+			if strings.HasPrefix(c.Id, toComplete) {
+				completions = append(completions, c.Id+"\t"+c.Description)
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completePersons completes the persons in the plan file given by the flag.
+func completePersons(planFlag string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		plan, err := internal.ReadPlan(completionPlanPath(cmd, planFlag))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return plan.Persons(), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeActivities completes activity IDs and numbers from the plan file
+// given by the flag, described by their name and time.
+func completeActivities(planFlag string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		plan, err := internal.ReadPlan(completionPlanPath(cmd, planFlag))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		completions := []string{}
+		for _, cw := range plan.Centers {
+			for _, e := range cw.Events {
+				description := fmt.Sprintf("%v, %v %v", e.Name, e.DayOfWeek, e.TimeRange)
+				for _, value := range []string{strconv.Itoa(e.Id), e.Number} {
+					if value == "" || slices.Contains(args, value) || !strings.HasPrefix(value, toComplete) {
+						continue
+					}
+					completions = append(completions, value+"\t"+description)
+				}
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeProfiles completes the profile names from the config files.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	c, err := internal.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := []string{}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionPlanPath returns the plan file while completing, when the config
+// hasn't been loaded: the flag if it was given, the profile's output or
+// output.json.
+func completionPlanPath(cmd *cobra.Command, planFlag string) string {
+	flag := cmd.Flags().Lookup(planFlag)
+	if flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+
+	c, err := internal.LoadConfig()
+	if err == nil {
+		p, err := c.Profile(profile)
+		if err == nil && p.Output != "" {
+			return p.Output
+		}
+	}
+
+	if flag != nil && flag.DefValue != "" {
+		return flag.DefValue
+	}
+	return "output.json"
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	completionCmd.Flags().Bool("install", false, "Install the script instead of printing it")
+}
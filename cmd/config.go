@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long:  `Commands for inspecting the configuration read from config files and the environment.`,
+}
+
+type configSummary struct {
+	Sources []string         `yaml:"sources"`
+	BaseUrl string           `yaml:"base_url"`
+	Profile string           `yaml:"profile,omitempty"`
+	Values  internal.Profile `yaml:"values"`
+
+	internal.Config `yaml:",inline"`
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the merged configuration",
+	Long: `Print the configuration after merging the config files and the environment.
+
+The values are the defaults for the search flags, overridden by the profile
+selected with --profile and then by the environment. They are followed by every
+section of the merged config, with the SMTP password and the webhook URL
+redacted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		values, err := config.Profile(profile)
+		if err != nil {
+			return usageErr(err)
+		}
+
+		summary := configSummary{
+			Sources: config.Sources,
+			BaseUrl: config.BaseUrl(),
+			Profile: profile,
+			Values:  values,
+			Config:  config.Redacted(),
+		}
+		if summary.Tenant == "" {
+			summary.Tenant = internal.DefaultTenant
+		}
+
+		out, err := yaml.Marshal(summary)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(string(out))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// costCmd represents the cost command
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "Total the fees of the selected activities",
+	Long: `Total the fees of the activities selected for each person, per person, per
+center or per activity, with the family total last.
+
+Discounts and budgets are read from the cost section of the config:
+
+  cost:
+    persons: [ben, ana]
+    budget: 600
+    budgets:
+      ana: 250
+    discounts:
+      - name: second child
+        percent: 10
+        from_person: 2
+      - name: subsidy
+        percent: 50
+        persons: [ben]
+
+Persons are reported and counted by from_person in the order of persons, eldest
+first, followed by those it doesn't list in alphabetical order. Without
+persons, the second child is the second name in alphabetical order.
+
+The percentages of the discounts that apply to a person add up. A total over
+its budget is flagged and logged as a warning. Activities whose fee isn't known
+count as free and are logged too.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		by, err := cmd.Flags().GetString("by")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{models.CostByPerson, models.CostByCenter, models.CostByActivity}, by) {
+			return usageErrorf("unexpected grouping %v, expected person, center or activity", by)
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.FormatTable, internal.FormatJSON, internal.FormatCSV}, format) {
+			return usageErrorf("unexpected format %v, expected table, json or csv", format)
+		}
+
+		shortlisted, err := cmd.Flags().GetBool("shortlisted")
+		if err != nil {
+			return err
+		}
+
+		plan, err := internal.ReadPlan(inputPath)
+		if err != nil {
+			return err
+		}
+
+		options := config.Cost.Options()
+		options.Shortlisted = shortlisted
+		report := models.NewCostReport(plan, options)
+
+		if report.Family.Unknown > 0 {
+			slog.Warn("some fees aren't known, load the activities again to get them", "activities", report.Family.Unknown)
+		}
+		for _, t := range report.OverBudget() {
+			slog.Warn("over budget", "name", t.Name, "total", t.Total.String(), "budget", t.Budget.String())
+		}
+
+		return writeCostReport(report, by, format)
+	},
+}
+
+func writeCostReport(report *models.CostReport, by string, format string) error {
+	if format == internal.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if by == models.CostByActivity {
+		table := internal.Table{Header: []string{"PERSON", "CENTER", "NAME", "NUMBER", "FEE", "DISCOUNT", "TOTAL"}}
+		for _, l := range report.Lines {
+			table.Append(l.Person, l.CenterName, l.Activity.Name, l.Activity.Number, costFee(l), l.Discount.String(), l.Total.String())
+		}
+		f := report.Family
+		table.Append("FAMILY", "", "", "", f.Fee.String(), f.Discount.String(), f.Total.String())
+		return table.Write(os.Stdout, format)
+	}
+
+	totals := report.Persons
+	table := internal.Table{Header: []string{"PERSON", "ACTIVITIES", "FEE", "DISCOUNT", "TOTAL", "BUDGET", "OVER BUDGET"}}
+	if by == models.CostByCenter {
+		totals = report.Centers
+		table.Header = []string{"CENTER", "ACTIVITIES", "FEE", "DISCOUNT", "TOTAL"}
+	}
+
+	for _, t := range append(slices.Clone(totals), report.Family) {
+		name := t.Name
+		if t == report.Family {
+			name = strings.ToUpper(name)
+		}
+		row := []string{name, strconv.Itoa(t.Activities), t.Fee.String(), t.Discount.String(), t.Total.String()}
+		if by == models.CostByPerson {
+			row = append(row, costBudget(t), costOverBudget(t))
+		}
+		table.Append(row...)
+	}
+	return table.Write(os.Stdout, format)
+}
+
+// costFee shows unknown fees as such rather than as free.
+func costFee(l *models.CostLine) string {
+	if l.FeeUnknown {
+		return "?"
+	}
+	return l.Fee.String()
+}
+
+func costBudget(t *models.CostTotal) string {
+	if t.Budget == 0 {
+		return ""
+	}
+	return t.Budget.String()
+}
+
+func costOverBudget(t *models.CostTotal) string {
+	if !t.OverBudget {
+		return ""
+	}
+	return (t.Total - t.Budget).String()
+}
+
+func init() {
+	rootCmd.AddCommand(costCmd)
+
+	costCmd.Flags().String("input", "output.json", "The plan file to read")
+	costCmd.Flags().String("by", models.CostByPerson, "Total the fees by person, center or activity")
+	costCmd.Flags().Bool("shortlisted", false, "Include the shortlisted activities with the selected")
+	costCmd.Flags().String("format", internal.FormatTable, "The output format: table, json or csv")
+	costCmd.RegisterFlagCompletionFunc("by", cobra.FixedCompletions([]string{models.CostByPerson, models.CostByCenter, models.CostByActivity}, cobra.ShellCompDirectiveNoFileComp))
+}
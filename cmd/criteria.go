@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// SearchOptions are the search criteria shared by the commands that search
+// for activities. With --within, centers are the centers near home searched
+// instead of center.
+type SearchOptions struct {
+	season       models.Criterium
+	center       models.Criterium
+	centers      models.Criteria
+	category     models.Criterium
+	searchString string
+	// available are the availabilities the activities found must fit in.
+	available []models.Availability
+}
+
+func addSearchFlags(cmd *cobra.Command) {
+	cmd.Flags().String("season", "", "The season ID or name")
+	cmd.Flags().String("center", "", "The center ID or name")
+	cmd.Flags().String("category", "", "The category ID or name")
+	cmd.Flags().String("search", "", "The search string")
+	cmd.Flags().String("within", "", "Search every center within the distance of home instead of --center, e.g. 5km")
+	cmd.Flags().String("available-for", "", "Only keep activities that fit the availability of the person and the drivers")
+	cmd.RegisterFlagCompletionFunc("season", completeCriteria("seasons"))
+	cmd.RegisterFlagCompletionFunc("center", completeCriteria("centers"))
+	cmd.RegisterFlagCompletionFunc("category", completeCriteria("categories"))
+	cmd.Flags().Bool("non-interactive", false, "Fail instead of prompting for missing or unknown criteria, the default when stdin isn't a terminal")
+}
+
+func getSearchOptions(cmd *cobra.Command) (*SearchOptions, error) {
+	options := internal.GetFiltersOptions{
+		BaseUrl: config.BaseUrl(),
+		NoCache: noCache,
+	}
+	filters, err := internal.GetFilters(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// each of these may be an ID, a name or fragments of a name
+	seasonId, err := cmd.Flags().GetString("season")
+	if err != nil {
+		return nil, err
+	}
+
+	centerId, err := cmd.Flags().GetString("center")
+	if err != nil {
+		return nil, err
+	}
+
+	categoryId, err := cmd.Flags().GetString("category")
+	if err != nil {
+		return nil, err
+	}
+
+	searchString, err := cmd.Flags().GetString("search")
+	if err != nil {
+		return nil, err
+	}
+
+	within, err := cmd.Flags().GetString("within")
+	if err != nil {
+		return nil, err
+	}
+
+	availableFor, err := cmd.Flags().GetString("available-for")
+	if err != nil {
+		return nil, err
+	}
+	available, err := getAvailability(availableFor)
+	if err != nil {
+		return nil, err
+	}
+
+	nonInteractive, err := cmd.Flags().GetBool("non-interactive")
+	if err != nil {
+		return nil, err
+	}
+	interactive := !nonInteractive && term.IsTerminal(int(os.Stdin.Fd()))
+
+	profile := internal.Profile{
+		Season:   seasonId,
+		Center:   centerId,
+		Category: categoryId,
+		Search:   searchString,
+	}
+	var searchOptions *SearchOptions
+	if within != "" {
+		searchOptions, err = resolveNearbyOptions(filters, profile, within, interactive)
+	} else {
+		searchOptions, err = resolveSearchOptions(filters, profile, interactive)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	searchOptions.available = available
+	return searchOptions, nil
+}
+
+// getAvailability returns the availability of the person, if any, and that
+// of the drivers.
+func getAvailability(person string) ([]models.Availability, error) {
+	if person == "" {
+		return nil, nil
+	}
+
+	availabilities := []models.Availability{}
+	availability, ok, err := config.PersonAvailability(person)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		availabilities = append(availabilities, availability)
+	}
+
+	drivers, driversOk, err := config.PersonAvailability(models.DriversKey)
+	if err != nil {
+		return nil, err
+	}
+	if driversOk && person != models.DriversKey {
+		availabilities = append(availabilities, drivers)
+	}
+
+	if !ok && !driversOk {
+		return nil, usageErrorf("no availability of %v or %v in the config", person, models.DriversKey)
+	}
+	return availabilities, nil
+}
+
+// resolveSearchOptions resolves the search criteria of the profile.
+func resolveSearchOptions(filters models.FiltersBody, p internal.Profile, interactive bool) (*SearchOptions, error) {
+	season, err := resolveCriterium("season", "Seasons", filters.Seasons, p.Season, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	center, err := resolveCriterium("center", "Center", filters.Centers, p.Center, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := resolveCriterium("category", "Category", filters.Categories, p.Category, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchOptions{
+		season:       season,
+		center:       center,
+		category:     category,
+		searchString: p.Search,
+	}, nil
+}
+
+// resolveNearbyOptions resolves the search criteria of the profile except
+// the center, searching every center within the distance of home instead.
+func resolveNearbyOptions(filters models.FiltersBody, p internal.Profile, within string, interactive bool) (*SearchOptions, error) {
+	radius, err := models.ParseDistance(within)
+	if err != nil {
+		return nil, usageErr(err)
+	}
+	if config.Home == nil {
+		return nil, usageErrorf("--within needs a home location in the config")
+	}
+
+	season, err := resolveCriterium("season", "Seasons", filters.Seasons, p.Season, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := resolveCriterium("category", "Category", filters.Categories, p.Category, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	// centers are only near home once their locations have been looked up
+	err = internal.EnsureCenterLocations(config.BaseUrl(), filters.Centers)
+	if err != nil {
+		slog.Warn("couldn't look up some center locations, add them to "+internal.DefaultLocationsPath, "error", err)
+	}
+
+	distances, err := config.CenterDistances()
+	if err != nil {
+		return nil, err
+	}
+
+	centers := models.CentersWithin(filters.Centers, distances, radius)
+	if len(centers) == 0 {
+		return nil, fmt.Errorf("no center within %v of home", within)
+	}
+	slog.Debug("found centers near home", "within", within, "count", len(centers))
+
+	return &SearchOptions{
+		season:       season,
+		centers:      centers,
+		category:     category,
+		searchString: p.Search,
+	}, nil
+}
+
+func (o *SearchOptions) request() models.ActivityRequest {
+	return o.centerRequest(o.center)
+}
+
+func (o *SearchOptions) centerRequest(center models.Criterium) models.ActivityRequest {
+	pattern := &models.ActivitySearchPattern{
+		SeasonIds:           []string{o.season.Id},
+		CenterIds:           []string{center.Id},
+		ActivityCategoryIds: []string{o.category.Id},
+		ActivityKeyword:     o.searchString,
+	}
+	models.NarrowSearch(pattern, o.available...)
+
+	return models.ActivityRequest{SearchPattern: pattern}
+}
+
+// search requests the activities matching the options from ActiveNet, one
+// center at a time, and records their openings in the history. Activities
+// outside the availabilities are dropped after they are recorded.
+func (o *SearchOptions) search() ([]*models.CenterWeek, error) {
+	centers := o.centers
+	if len(centers) == 0 {
+		centers = models.Criteria{o.center}
+	}
+
+	result := []*models.CenterWeek{}
+	for _, c := range centers {
+		request := o.centerRequest(c)
+		activities, err := internal.GetActivities(request, internal.GetActivitiesOptions{
+			BaseUrl: config.BaseUrl(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		internal.RecordOpenings(activities, request, c.Description)
+
+		filter := models.ActivityFilter{Availability: o.available}
+		activities, err = filter.Apply(activities)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &models.CenterWeek{CenterId: c.Id, CenterName: c.Description, Events: activities})
+	}
+
+	return result, nil
+}
+
+// resolveCriterium finds the criterium matching the query, which may be an
+// ID, a name or fragments of a name. When the query doesn't match exactly one
+// criterium, the user is prompted to choose between the candidates, or
+// between all criteria when there are none. Prompting is an error when not
+// interactive.
+func resolveCriterium(kind string, label string, criteria models.Criteria, query string, interactive bool) (models.Criterium, error) {
+	c, err := models.Find(kind, criteria, query)
+	if err == nil {
+		return c, nil
+	}
+
+	var lookupErr *models.LookupError
+	if !errors.As(err, &lookupErr) {
+		return models.Criterium{}, err
+	}
+
+	if !interactive {
+		if query == "" {
+			return models.Criterium{}, usageErrorf("--%v is required when running non-interactively", kind)
+		}
+		return models.Criterium{}, err
+	}
+
+	choices := criteria
+	if lookupErr.Ambiguous {
+		choices = lookupErr.Candidates
+	}
+	if query != "" {
+		slog.Warn("choose the "+kind+" from the list", "error", err)
+	}
+
+	items := []string{}
+	for _, c := range choices {
+		items = append(items, fmt.Sprintf("%v (%v)", c.Description, c.Id))
+	}
+
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+		Searcher: func(input string, index int) bool {
+			return strings.Contains(strings.ToLower(items[index]), strings.ToLower(input))
+		},
+	}
+
+	index, _, err := prompt.Run()
+	if err != nil {
+		return models.Criterium{}, err
+	}
+
+	return choices[index], nil
+}
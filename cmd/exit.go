@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+)
+
+// Exit codes, listed in the help of the root command.
+const (
+	exitError   = 1
+	exitUsage   = 2
+	exitNetwork = 3
+	exitPlan    = 4
+)
+
+// usageError is an invalid flag, argument or criterium.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// usageErr marks the error as caused by the command line. Nil stays nil.
+func usageErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err}
+}
+
+func usageErrorf(format string, a ...any) error {
+	return &usageError{fmt.Errorf(format, a...)}
+}
+
+// exitCode returns the process exit code for an error returned by a command.
+func exitCode(err error) int {
+	var usage *usageError
+	var lookup *models.LookupError
+	var request *internal.RequestError
+	var plan *internal.PlanError
+	switch {
+	case errors.As(err, &usage), errors.As(err, &lookup):
+		return exitUsage
+	case errors.As(err, &request):
+		return exitNetwork
+	case errors.As(err, &plan):
+		return exitPlan
+	}
+
+	return exitError
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+type filterRow struct {
+	List        string   `json:"list"`
+	Id          string   `json:"id"`
+	Description string   `json:"desc"`
+	Distance    *float64 `json:"distance_km,omitempty"`
+}
+
+// filtersCmd represents the filters command
+var filtersCmd = &cobra.Command{
+	Use:       "filters [list...]",
+	Short:     "List the search criteria",
+	ValidArgs: models.FilterLists,
+	Args:      cobra.OnlyValidArgs,
+	Long: `Print the search criteria available for the season, center and category
+flags, and the other criteria ActiveNet provides.
+
+The lists are seasons, centers, categories, activity-types, sites, age-groups,
+instructors and geographic-areas. Every list is printed when none is given.
+The criteria are read from the cache unless --refresh is given. Centers are
+shown with their distance from the home location of the config, when their
+location is known.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.FormatTable, internal.FormatJSON, internal.FormatCSV}, format) {
+			return usageErrorf("unexpected format %v, expected table, json or csv", format)
+		}
+
+		grep, err := cmd.Flags().GetString("grep")
+		if err != nil {
+			return err
+		}
+
+		refresh, err := cmd.Flags().GetBool("refresh")
+		if err != nil {
+			return err
+		}
+
+		var pattern *regexp.Regexp
+		if grep != "" {
+			pattern, err = regexp.Compile("(?i)" + grep)
+			if err != nil {
+				return usageErr(err)
+			}
+		}
+
+		filters, err := internal.GetFilters(internal.GetFiltersOptions{
+			BaseUrl: config.BaseUrl(),
+			NoCache: noCache || refresh,
+		})
+		if err != nil {
+			return err
+		}
+
+		distances, err := config.CenterDistances()
+		if err != nil {
+			return err
+		}
+
+		lists := args
+		if len(lists) == 0 {
+			lists = models.FilterLists
+		}
+
+		rows := []filterRow{}
+		for _, name := range lists {
+			criteria, err := filters.List(name)
+			if err != nil {
+				return usageErr(err)
+			}
+
+			for _, c := range criteria {
+				if pattern != nil && !pattern.MatchString(c.Description) && !pattern.MatchString(c.Id) {
+					continue
+				}
+				row := filterRow{List: name, Id: c.Id, Description: c.Description}
+				if d, ok := distances[c.Id]; ok && name == "centers" {
+					row.Distance = &d
+				}
+				rows = append(rows, row)
+			}
+		}
+
+		return writeFilterRows(rows, format, len(lists) > 1, distances != nil && slices.Contains(lists, "centers"))
+	},
+}
+
+func writeFilterRows(rows []filterRow, format string, showList bool, showDistance bool) error {
+	if format == internal.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	}
+
+	table := internal.Table{Header: []string{"ID", "NAME"}}
+	if showList {
+		table.Header = append([]string{"LIST"}, table.Header...)
+	}
+	if showDistance {
+		table.Header = append(table.Header, "DISTANCE")
+	}
+	for _, r := range rows {
+		row := []string{r.Id, r.Description}
+		if showList {
+			row = append([]string{r.List}, row...)
+		}
+		if showDistance {
+			distance := ""
+			if r.Distance != nil {
+				distance = fmt.Sprintf("%.1f km", *r.Distance)
+			}
+			row = append(row, distance)
+		}
+		table.Append(row...)
+	}
+
+	return table.Write(os.Stdout, format)
+}
+
+func init() {
+	rootCmd.AddCommand(filtersCmd)
+
+	filtersCmd.Flags().String("format", internal.FormatTable, "The output format: table, json or csv")
+	filtersCmd.Flags().String("grep", "", "Only print criteria whose name or ID matches the regular expression")
+	filtersCmd.Flags().Bool("refresh", false, "Fetch the criteria from ActiveNet and update the cache")
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/spf13/cobra"
+)
+
+type historyRow struct {
+	Version    int       `json:"version"`
+	Time       time.Time `json:"time"`
+	Path       string    `json:"path"`
+	Persons    []string  `json:"persons"`
+	Activities int       `json:"activities"`
+}
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the backups of the plan file",
+	Long: fmt.Sprintf(`List the earlier versions of the plan file, the most recent first.
+
+A backup is kept in .gojoin/backups next to the plan each time it's written,
+up to %d of them. Restore one with gojoin undo.`, internal.MaxBackups),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.FormatTable, internal.FormatJSON, internal.FormatCSV}, format) {
+			return usageErrorf("unexpected format %v, expected table, json or csv", format)
+		}
+
+		backups, err := internal.Backups(inputPath)
+		if err != nil {
+			return err
+		}
+
+		rows := []historyRow{}
+		for i, b := range backups {
+			row := historyRow{Version: i + 1, Time: b.Time, Path: b.Path, Persons: []string{}}
+			plan, err := internal.ReadPlan(b.Path)
+			if err == nil {
+				row.Persons = plan.Persons()
+				for _, cw := range plan.Centers {
+					row.Activities += len(cw.Events)
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		if format == internal.FormatJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(rows)
+		}
+
+		table := internal.Table{Header: []string{"VERSION", "TIME", "PERSONS", "ACTIVITIES"}}
+		for _, r := range rows {
+			table.Append(strconv.Itoa(r.Version), r.Time.Format(time.DateTime), strings.Join(r.Persons, ", "), strconv.Itoa(r.Activities))
+		}
+		return table.Write(os.Stdout, format)
+	},
+}
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore an earlier version of the plan file",
+	Long: `Replace the plan file with one of its backups, by default the most recent.
+
+The current version is backed up first, so running undo twice returns to where
+you started. Use gojoin history and --to to go further back.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		version, err := cmd.Flags().GetInt("to")
+		if err != nil {
+			return err
+		}
+
+		backups, err := internal.Backups(inputPath)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return &internal.PlanError{Path: inputPath, Err: fmt.Errorf("no backups found")}
+		}
+		if version < 1 || version > len(backups) {
+			return usageErrorf("unexpected version %v, expected 1 to %v", version, len(backups))
+		}
+
+		backup := backups[version-1]
+		err = internal.RestorePlan(inputPath, backup)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored %v from %v\n", inputPath, backup.Time.Format(time.DateTime))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(undoCmd)
+
+	historyCmd.Flags().String("input", "output.json", "The plan file")
+	historyCmd.Flags().String("format", internal.FormatTable, "The output format: table, json or csv")
+
+	undoCmd.Flags().String("input", "output.json", "The plan file")
+	undoCmd.Flags().Int("to", 1, "The version to restore, as numbered by gojoin history")
+}
@@ -1,66 +1,27 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"log/slog"
+	"slices"
 
-	"github.com/manifoldco/promptui"
 	"github.com/snocorp/gojoin/internal"
 	"github.com/snocorp/gojoin/models"
 	"github.com/spf13/cobra"
 )
 
 type LoadOptions struct {
-	season       models.Criterium
-	center       models.Criterium
-	category     models.Criterium
-	searchString string
-	outputPath   string
-	person       string
-	verbose      bool
+	*SearchOptions
+
+	outputPath string
+	person     string
 }
 
+// getOptions reads the flags of the load command except the search criteria,
+// which may need prompting.
 func getOptions(cmd *cobra.Command) (*LoadOptions, error) {
-	verbose, err := cmd.Flags().GetBool("verbose")
-	if err != nil {
-		return nil, err
-	}
-
-	noCache, err := cmd.Flags().GetBool("nocache")
-	if err != nil {
-		return nil, err
-	}
-
-	options := internal.GetFiltersOptions{
-		Verbose: verbose,
-		NoCache: noCache,
-	}
-	filters, err := internal.GetFilters(options)
-	if err != nil {
-		return nil, err
-	}
-
-	seasonId, err := cmd.Flags().GetString("season")
-	if err != nil {
-		return nil, err
-	}
-
-	centerId, err := cmd.Flags().GetString("center")
-	if err != nil {
-		return nil, err
-	}
-
-	categoryId, err := cmd.Flags().GetString("category")
-	if err != nil {
-		return nil, err
-	}
-
-	searchString, err := cmd.Flags().GetString("search")
-	if err != nil {
-		return nil, err
-	}
-
 	outputPath, err := cmd.Flags().GetString("output")
 	if err != nil {
 		return nil, err
@@ -71,29 +32,9 @@ func getOptions(cmd *cobra.Command) (*LoadOptions, error) {
 		return nil, err
 	}
 
-	season, err := promptSeason(filters, seasonId)
-	if err != nil {
-		return nil, err
-	}
-
-	center, err := promptCenter(filters, centerId)
-	if err != nil {
-		return nil, err
-	}
-
-	category, err := promptCategory(filters, categoryId)
-	if err != nil {
-		return nil, err
-	}
-
 	return &LoadOptions{
-		season:       season,
-		center:       center,
-		category:     category,
-		searchString: searchString,
-		outputPath:   outputPath,
-		person:       person,
-		verbose:      verbose,
+		outputPath: outputPath,
+		person:     person,
 	}, nil
 }
 
@@ -101,204 +42,85 @@ func getOptions(cmd *cobra.Command) (*LoadOptions, error) {
 var loadCmd = &cobra.Command{
 	Use:   "load",
 	Short: "Load data for actitvities",
-	Long:  `Requests data using the given search criteria and stores it in the output file.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		options, err := getOptions(cmd)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
+	Long: `Requests data using the given search criteria and stores it in the output file.
 
-		req := models.ActivityRequest{
-			SearchPattern: &models.ActivitySearchPattern{
-				SeasonIds:           []string{options.season.Id},
-				CenterIds:           []string{options.center.Id},
-				ActivityCategoryIds: []string{options.category.Id},
-				ActivityKeyword:     options.searchString,
-			},
-		}
+With --within, every center within the distance of the home location of the
+config is searched instead of --center, e.g. --within 5km. With
+--available-for, only the activities that fit the availability of the person
+and of the drivers in the config are loaded.
 
-		activities, err := internal.GetActivities(req, internal.GetActivitiesOptions{
-			Verbose: options.verbose,
-		})
+An existing output file that can't be parsed is left untouched and the command
+fails, see gojoin validate.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := getOptions(cmd)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
 
 		if options.outputPath == "" {
 			options.outputPath = "output.json"
 		}
 
-		var existingPlan models.Plan
-		plan := models.Plan{Plans: []*models.PersonCenterWeek{}}
-		outputBytes, err := os.ReadFile(options.outputPath)
-		if err != nil {
-			if options.verbose {
-				fmt.Println("unable to read output file to load existing data")
-			}
+		// a plan that can't be parsed is never overwritten
+		_, err = internal.ReadPlan(options.outputPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Debug("no existing plan, creating it", "path", options.outputPath)
+		} else if err != nil {
+			return fmt.Errorf("%w, check it with gojoin validate --input %v", err, options.outputPath)
 		}
 
-		err = json.Unmarshal(outputBytes, &existingPlan)
+		options.SearchOptions, err = getSearchOptions(cmd)
 		if err != nil {
-			if options.verbose {
-				fmt.Println("unable to unmarshal existing data")
-			}
+			return err
 		}
 
-		if options.verbose {
-			fmt.Printf("Found %v activities\n", len(activities))
+		centers, err := options.search()
+		if err != nil {
+			return err
 		}
 
-		var personWeek *models.PersonCenterWeek
-		foundPlan := false
-		for _, p := range existingPlan.Plans {
-
-			if p.Person == options.person {
-				if options.verbose {
-					fmt.Printf("Found plan for %v\n", options.person)
-				}
-
-				foundCenterWeek := false
-				for _, cw := range p.CenterWeeks {
-					if cw.CenterId == options.center.Id {
-						cw.Events = activities
-						foundCenterWeek = true
-					}
-				}
-				if !foundCenterWeek {
-					p.CenterWeeks = append(p.CenterWeeks, &models.CenterWeek{
-						CenterId:   options.center.Id,
-						CenterName: options.center.Description,
-						Events:     activities,
-					})
-				}
-				foundPlan = true
-			}
-
-			plan.Plans = append(plan.Plans, p)
+		for _, cw := range centers {
+			slog.Debug("found activities", "center", cw.CenterName, "count", len(cw.Events))
 		}
-		if !foundPlan {
-			if options.verbose {
-				fmt.Printf("Creating plan for %v\n", options.person)
+
+		// the location is used to estimate travel times between centers, and
+		// is already known for the centers found near home
+		if len(options.centers) == 0 {
+			err = internal.EnsureCenterLocation(config.BaseUrl(), options.center.Id, options.center.Description)
+			if err != nil {
+				slog.Warn("couldn't look up the center location, add it to "+internal.DefaultLocationsPath, "center", options.center.Description, "error", err)
 			}
-			personWeek = &models.PersonCenterWeek{Person: options.person, CenterWeeks: []*models.CenterWeek{
-				{CenterId: options.center.Id, CenterName: options.center.Description, Events: activities},
-			}}
-			plan.Plans = append(plan.Plans, personWeek)
 		}
 
-		if options.verbose {
-			fmt.Printf("Found %v activities\n", len(plan.Plans[0].CenterWeeks[0].Events))
-		}
+		// the plan is read again under the lock in case it changed during
+		// the search
+		return internal.UpdatePlan(options.outputPath, func(plan *models.Plan) error {
+			if slices.Contains(plan.Persons(), options.person) {
+				slog.Debug("found plan", "person", options.person)
+			}
 
-		planJson, err := json.Marshal(plan)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
+			for _, cw := range centers {
+				// centers near home without activities aren't added
+				inPlan := slices.ContainsFunc(plan.Centers, func(c *models.CenterWeek) bool { return c.CenterId == cw.CenterId })
+				if len(options.centers) > 0 && len(cw.Events) == 0 && !inPlan {
+					continue
+				}
 
-		err = os.WriteFile(options.outputPath, planJson, 0664)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
+				plan.SetActivities(options.person, cw.CenterId, cw.CenterName, cw.Events)
+			}
+			return nil
+		})
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(loadCmd)
 
-	loadCmd.Flags().String("season", "", "The season ID")
-	loadCmd.Flags().String("center", "", "The center ID")
-	loadCmd.Flags().String("category", "", "The category ID")
-	loadCmd.Flags().String("search", "", "The search string")
+	addSearchFlags(loadCmd)
+	acceptProfileKeys(loadCmd, slices.Concat(searchProfileKeys, []string{"output", "person"})...)
 	loadCmd.Flags().String("output", "", "The output file for the loaded data")
 
 	loadCmd.Flags().String("person", "", "The person with which the events will be associated")
 	loadCmd.MarkFlagRequired("person")
-
-	loadCmd.Flags().Bool("verbose", false, "Enable verbose output")
-	loadCmd.Flags().Bool("nocache", false, "Disable cache for filters")
-}
-
-func promptSeason(filters models.FiltersBody, defaultId string) (models.Criterium, error) {
-	if defaultId != "" {
-		for _, s := range filters.Seasons {
-			if s.Id == defaultId {
-				return s, nil
-			}
-		}
-	}
-
-	seasonItems := []string{}
-	for _, s := range filters.Seasons {
-		seasonItems = append(seasonItems, fmt.Sprintf("%v (%v)", s.Description, s.Id))
-	}
-
-	prompt := promptui.Select{
-		Label: "Seasons",
-		Items: seasonItems,
-	}
-
-	index, _, err := prompt.Run()
-	if err != nil {
-		return models.Criterium{}, err
-	}
-
-	return filters.Seasons[index], nil
-}
-
-func promptCenter(filters models.FiltersBody, defaultId string) (models.Criterium, error) {
-	if defaultId != "" {
-		for _, c := range filters.Centers {
-			if c.Id == defaultId {
-				return c, nil
-			}
-		}
-	}
-
-	centerItems := []string{}
-	for _, c := range filters.Centers {
-		centerItems = append(centerItems, fmt.Sprintf("%v (%v)", c.Description, c.Id))
-	}
-
-	prompt := promptui.Select{
-		Label: "Center",
-		Items: centerItems,
-	}
-
-	index, _, err := prompt.Run()
-	if err != nil {
-		return models.Criterium{}, err
-	}
-
-	return filters.Centers[index], nil
-}
-
-func promptCategory(filters models.FiltersBody, defaultId string) (models.Criterium, error) {
-	if defaultId != "" {
-		for _, c := range filters.Categories {
-			if c.Id == defaultId {
-				return c, nil
-			}
-		}
-	}
-
-	categoryItems := []string{}
-	for _, c := range filters.Categories {
-		categoryItems = append(categoryItems, fmt.Sprintf("%v (%v)", c.Description, c.Id))
-	}
-
-	prompt := promptui.Select{
-		Label: "Category",
-		Items: categoryItems,
-	}
-
-	index, _, err := prompt.Run()
-	if err != nil {
-		return models.Criterium{}, err
-	}
-
-	return filters.Categories[index], nil
+	loadCmd.RegisterFlagCompletionFunc("person", completePersons("output"))
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the plan file to the current schema version",
+	Long: fmt.Sprintf(`Upgrade the plan file to schema version %v, keeping a backup of the old file.
+
+Older plan files are upgraded in memory whenever they're read, so migrating is
+only needed to rewrite the file. --schema prints the JSON Schema of the
+current version instead.`, models.SchemaVersion),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printSchema, err := cmd.Flags().GetBool("schema")
+		if err != nil {
+			return err
+		}
+		if printSchema {
+			_, err = os.Stdout.Write(internal.PlanSchema)
+			return err
+		}
+
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(inputPath)
+		if err != nil {
+			return &internal.PlanError{Path: inputPath, Err: err}
+		}
+
+		version, err := models.PlanVersion(data)
+		if err != nil {
+			return &internal.PlanError{Path: inputPath, Err: err}
+		}
+		if version > models.SchemaVersion {
+			return &internal.PlanError{Path: inputPath, Err: fmt.Errorf("schema version %v is newer than this version of gojoin supports, %v", version, models.SchemaVersion)}
+		}
+		if version == models.SchemaVersion {
+			fmt.Printf("%v is already at schema version %v\n", inputPath, version)
+			return nil
+		}
+
+		for _, m := range models.PendingMigrations(version) {
+			fmt.Printf("%v -> %v: %v\n", m.From, m.From+1, m.Description)
+		}
+		if dryRun {
+			return nil
+		}
+
+		err = internal.UpdatePlan(inputPath, func(plan *models.Plan) error {
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Migrated %v from schema version %v to %v\n", inputPath, version, models.SchemaVersion)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().String("input", "output.json", "The plan file to upgrade")
+	migrateCmd.Flags().Bool("dry-run", false, "Only list the migrations that would be applied")
+	migrateCmd.Flags().Bool("schema", false, "Print the JSON Schema of the plan file")
+}
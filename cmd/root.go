@@ -1,27 +1,160 @@
 package cmd
 
 import (
+	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/snocorp/gojoin/internal"
 	"github.com/spf13/cobra"
 )
 
 var verbose bool
 var noCache bool
+var profile string
+var logLevel string
+var logFormat string
+
+// config is the merged configuration, loaded before any command runs
+var config *internal.Config
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "gojoin",
 	Short: "Tool for visualizing weekly activities",
-	Long:  `A tool to load and then view activities in a weekly format.`,
+	Long: `A tool to load and then view activities in a weekly format.
+
+Defaults for the search flags are read from config.yaml or config.toml in the
+gojoin user config directory, and then from .gojoin.yaml or .gojoin.toml in the
+working directory. Named profiles are selected with --profile. Environment
+variables such as GOJOIN_TENANT and GOJOIN_CENTER override the config files and
+flags override everything.
+
+Logs are written to stderr, as text or as JSON with --log-format json.
+--verbose is short for --log-level debug.
+
+Exit codes:
+  0  success
+  1  unexpected error
+  2  invalid flags, arguments or search criteria
+  3  ActiveNet couldn't be reached or answered unexpectedly
+  4  the plan file couldn't be read or written`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := setupLogging(cmd)
+		if err != nil {
+			return err
+		}
+
+		config, err = internal.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return usageErr(applyProfile(cmd, config, profile))
+	},
+}
+
+// profileKeysAnnotation lists the profile keys whose values a command takes
+// for its flags of the same name, separated by commas.
+const profileKeysAnnotation = "gojoin-profile-keys"
+
+// searchProfileKeys are the profile keys of the search criteria.
+var searchProfileKeys = []string{"season", "center", "category", "search"}
+
+// acceptProfileKeys lets the profile set the flags of the command named by
+// the keys. The profile leaves the other flags alone, so that a key such as
+// output only applies to the commands it is meant for.
+func acceptProfileKeys(cmd *cobra.Command, keys ...string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[profileKeysAnnotation] = strings.Join(keys, ",")
+}
+
+// applyProfile sets the flags of the command that weren't given on the
+// command line to the values of the profile, for the keys the command
+// accepts.
+func applyProfile(cmd *cobra.Command, config *internal.Config, name string) error {
+	p, err := config.Profile(name)
+	if err != nil {
+		return err
+	}
+
+	accepted := cmd.Annotations[profileKeysAnnotation]
+	if accepted == "" {
+		return nil
+	}
+
+	values := p.Values()
+	for _, flagName := range strings.Split(accepted, ",") {
+		value := values[flagName]
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil || flag.Changed || value == "" {
+			continue
+		}
+
+		err = cmd.Flags().Set(flagName, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setupLogging installs the default logger, writing to stderr at the level
+// and in the format given by the flags.
+func setupLogging(cmd *cobra.Command) error {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(logLevel))
+	if err != nil {
+		return usageErrorf("unexpected log level %v, expected debug, info, warn or error", logLevel)
+	}
+	if verbose && !cmd.Flags().Changed("log-level") {
+		level = slog.LevelDebug
+	}
+
+	handler, err := newLogHandler(level, logFormat)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func newLogHandler(level slog.Level, format string) (slog.Handler, error) {
+	options := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "text":
+		// the time is noise for a command line tool
+		options.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		}
+		return slog.NewTextHandler(os.Stderr, options), nil
+	case "json":
+		return slog.NewJSONHandler(os.Stderr, options), nil
+	}
+
+	return nil, usageErrorf("unexpected log format %v, expected text or json", format)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// errors parsing the command line are logged before the flags are read
+	handler, _ := newLogHandler(slog.LevelInfo, "text")
+	slog.SetDefault(slog.New(handler))
+
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		slog.Error(err.Error())
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -30,8 +163,17 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Output verbose information")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Output verbose information, the same as --log-level debug")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "The minimum level logged: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "The log format: text or json")
 	rootCmd.PersistentFlags().BoolVar(&noCache, "nocache", false, "Do not use cached data")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", os.Getenv("GOJOIN_PROFILE"), "The config profile to use")
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageErr(err)
+	})
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyProfileKeys(t *testing.T) {
+	c := &internal.Config{Defaults: internal.Profile{Output: "plan.json", Person: "ann", Season: "46"}}
+
+	// fresh flags, as the commands are shared by every test
+	newCmd := func(from *cobra.Command) *cobra.Command {
+		cmd := &cobra.Command{Use: from.Use, Annotations: from.Annotations}
+		cmd.Flags().AddFlagSet(from.LocalNonPersistentFlags())
+		for _, name := range []string{"output", "person", "season"} {
+			if flag := cmd.Flags().Lookup(name); flag != nil {
+				flag.Value.Set(flag.DefValue)
+				flag.Changed = false
+			}
+		}
+		return cmd
+	}
+
+	view := newCmd(viewCmd)
+	err := applyProfile(view, c, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := view.Flags().GetString("output")
+	if output != "" {
+		t.Errorf("Expected view to ignore the profile's output but got %q", output)
+	}
+
+	load := newCmd(loadCmd)
+	err = applyProfile(load, c, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ = load.Flags().GetString("output")
+	season, _ := load.Flags().GetString("season")
+	if output != "plan.json" || season != "46" {
+		t.Errorf("Expected load to take the profile's output and season but got %q and %q", output, season)
+	}
+
+	search := newCmd(searchCmd)
+	err = applyProfile(search, c, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	season, _ = search.Flags().GetString("season")
+	if season != "46" {
+		t.Errorf("Expected search to take the profile's season but got %q", season)
+	}
+}
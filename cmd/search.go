@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatAgenda = "agenda"
+	sortDistance = "distance"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search for activities without saving them",
+	Long: `Requests data using the given search criteria, like load, and prints the
+activities instead of storing them. The plan file is neither read nor written.
+
+The results can be narrowed down by day, start time, name and level, and sorted
+by day, time, name, number or ID. The level is parsed from the activity name,
+see the names section of the config.
+
+With --within, every center within the distance of the home location of the
+config is searched instead of --center, and each activity is shown with its
+center and distance. The JSON output then lists the centers with their
+activities. Results are sorted by distance with --sort distance.
+
+With --available-for, only the activities that fit the availability of the
+person and of the drivers, set in the availability section of the config, are
+kept. The days and times of the search are narrowed to match where possible.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.FormatTable, internal.FormatJSON, internal.FormatCSV, formatAgenda}, format) {
+			return usageErrorf("unexpected format %v, expected table, json, csv or agenda", format)
+		}
+
+		sortKey, err := cmd.Flags().GetString("sort")
+		if err != nil {
+			return err
+		}
+
+		if sortKey == sortDistance && config.Home == nil {
+			return usageErrorf("sorting by distance needs a home location in the config")
+		}
+
+		filter, err := getActivityFilter(cmd)
+		if err != nil {
+			return usageErr(err)
+		}
+
+		options, err := getSearchOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		centers, err := options.search()
+		if err != nil {
+			return err
+		}
+
+		distances, err := config.CenterDistances()
+		if err != nil {
+			return err
+		}
+
+		activities := []*models.Activity{}
+		centerOf := map[*models.Activity]*models.CenterWeek{}
+		for _, cw := range centers {
+			cw.Events, err = filter.Apply(cw.Events)
+			if err != nil {
+				return err
+			}
+			for _, a := range cw.Events {
+				centerOf[a] = cw
+			}
+			activities = append(activities, cw.Events...)
+		}
+
+		err = sortActivities(activities, sortKey, func(a *models.Activity) (float64, bool) {
+			d, ok := distances[centerOf[a].CenterId]
+			return d, ok
+		})
+		if err != nil {
+			return usageErr(err)
+		}
+
+		// the events of each center follow the sort order
+		for _, cw := range centers {
+			cw.Events = slices.DeleteFunc(slices.Clone(activities), func(a *models.Activity) bool { return centerOf[a] != cw })
+		}
+
+		slog.Debug("found activities", "count", len(activities))
+
+		if len(options.centers) == 0 {
+			return writeActivities(cmd, activities, options.center, format)
+		}
+		return writeNearbyActivities(cmd, centers, activities, centerOf, distances, format)
+	},
+}
+
+// sortActivities sorts the activities by one of the models Sort keys, or by
+// the distance of their center from home and then by day, with unknown
+// distances last.
+func sortActivities(activities []*models.Activity, key string, distance func(*models.Activity) (float64, bool)) error {
+	if key != sortDistance {
+		return models.SortActivities(activities, key)
+	}
+
+	err := models.SortActivities(activities, models.SortDay)
+	if err != nil {
+		return err
+	}
+
+	slices.SortStableFunc(activities, func(a, b *models.Activity) int {
+		da, aOk := distance(a)
+		db, bOk := distance(b)
+		if aOk != bOk {
+			if aOk {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(da, db)
+	})
+	return nil
+}
+
+func getActivityFilter(cmd *cobra.Command) (*models.ActivityFilter, error) {
+	filter := &models.ActivityFilter{}
+
+	days, err := cmd.Flags().GetStringSlice("day")
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range days {
+		day, err := models.ParseWeekday(d)
+		if err != nil {
+			return nil, err
+		}
+		filter.Days = append(filter.Days, day)
+	}
+
+	startAfter, err := cmd.Flags().GetString("start-after")
+	if err != nil {
+		return nil, err
+	}
+	if startAfter != "" {
+		tod, err := models.ParseTimeOfDay(startAfter)
+		if err != nil {
+			return nil, err
+		}
+		filter.StartAfter = &tod
+	}
+
+	startBefore, err := cmd.Flags().GetString("start-before")
+	if err != nil {
+		return nil, err
+	}
+	if startBefore != "" {
+		tod, err := models.ParseTimeOfDay(startBefore)
+		if err != nil {
+			return nil, err
+		}
+		filter.StartBefore = &tod
+	}
+
+	filter.Levels, err = cmd.Flags().GetStringSlice("level")
+	if err != nil {
+		return nil, err
+	}
+	if len(filter.Levels) > 0 {
+		filter.Names, err = config.NameRules()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		filter.Name, err = regexp.Compile("(?i)" + name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+func writeActivities(cmd *cobra.Command, activities []*models.Activity, center models.Criterium, format string) error {
+	switch format {
+	case internal.FormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(activities)
+	case formatAgenda:
+		style, err := cmd.Flags().GetString("agenda-style")
+		if err != nil {
+			return err
+		}
+
+		plan := &models.Plan{Centers: []*models.CenterWeek{}}
+		plan.Center(center.Id, center.Description).Events = activities
+
+		agenda, err := models.NewAgenda(plan, models.AgendaByCenter, models.Ordering{Activities: models.OrderName})
+		if err != nil {
+			return err
+		}
+
+		return internal.RenderAgenda(os.Stdout, agenda, style)
+	}
+
+	table := internal.Table{Header: []string{"DAY", "TIME", "NAME", "NUMBER", "ID"}}
+	if format == internal.FormatCSV {
+		table.Header = append(table.Header, "URL")
+	}
+	for _, a := range activities {
+		row := []string{a.DayOfWeek, a.TimeRange, a.Name, a.Number, strconv.Itoa(a.Id)}
+		if format == internal.FormatCSV {
+			row = append(row, a.DetailUrl)
+		}
+		table.Append(row...)
+	}
+
+	return table.Write(os.Stdout, format)
+}
+
+// writeNearbyActivities writes the activities found at the centers near home
+// with their center and its distance. JSON lists the centers with their
+// activities.
+func writeNearbyActivities(cmd *cobra.Command, centers []*models.CenterWeek, activities []*models.Activity, centerOf map[*models.Activity]*models.CenterWeek, distances map[string]float64, format string) error {
+	switch format {
+	case internal.FormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(centers)
+	case formatAgenda:
+		style, err := cmd.Flags().GetString("agenda-style")
+		if err != nil {
+			return err
+		}
+
+		plan := &models.Plan{Centers: centers}
+		agenda, err := models.NewAgenda(plan, models.AgendaByCenter, models.Ordering{Activities: models.OrderName})
+		if err != nil {
+			return err
+		}
+
+		return internal.RenderAgenda(os.Stdout, agenda, style)
+	}
+
+	table := internal.Table{Header: []string{"DAY", "TIME", "NAME", "NUMBER", "ID", "CENTER", "DISTANCE"}}
+	if format == internal.FormatCSV {
+		table.Header = append(table.Header, "URL")
+	}
+	for _, a := range activities {
+		cw := centerOf[a]
+		row := []string{a.DayOfWeek, a.TimeRange, a.Name, a.Number, strconv.Itoa(a.Id), cw.CenterName, formatDistance(distances, cw.CenterId)}
+		if format == internal.FormatCSV {
+			row = append(row, a.DetailUrl)
+		}
+		table.Append(row...)
+	}
+
+	return table.Write(os.Stdout, format)
+}
+
+// formatDistance formats the distance of the center from home, empty when
+// it is unknown.
+func formatDistance(distances map[string]float64, centerId string) string {
+	d, ok := distances[centerId]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.1f km", d)
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	addSearchFlags(searchCmd)
+	acceptProfileKeys(searchCmd, searchProfileKeys...)
+
+	searchCmd.Flags().String("format", internal.FormatTable, "The output format: table, json, csv or agenda")
+	searchCmd.Flags().String("agenda-style", internal.AgendaText, "The agenda style: text, markdown or html")
+	searchCmd.Flags().String("sort", models.SortDay, "Sort by day, time, name, number, id or distance from home")
+	searchCmd.Flags().StringSlice("day", nil, "Only show activities on the given days, e.g. sat,sun")
+	searchCmd.Flags().String("start-after", "", "Only show activities starting at or after the time, e.g. 15:30 or 3:30 PM")
+	searchCmd.Flags().String("start-before", "", "Only show activities starting before the time")
+	searchCmd.Flags().String("name", "", "Only show activities whose name matches the regular expression")
+	searchCmd.Flags().StringSlice("level", nil, "Only show activities of the given levels, e.g. 4 or \"Swim Kids 4\"")
+}
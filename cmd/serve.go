@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the view over HTTP",
+	Long: `Start a local HTTP server that renders the loaded data on request.
+
+The page reloads in the browser whenever the input file or the templates change.
+The following routes are available:
+
+  /                  the week view for every center
+  /centers/{id}      the week view for a single center
+  /persons/{person}  the week view for a single person
+  /agenda            the agenda, optionally ?group-by=center or level, or ?person=name
+
+The week views show the cost, conflicts and unavailable times like gojoin view.
+
+With --api, a JSON API for managing the plan is served under /api/. It is
+described by /api/openapi.yaml.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		addr, err := cmd.Flags().GetString("addr")
+		if err != nil {
+			return err
+		}
+
+		enableAPI, err := cmd.Flags().GetBool("api")
+		if err != nil {
+			return err
+		}
+
+		ordering, err := getOrdering(cmd)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		travel, err := internal.LoadTravelTimes(config.BaseUrl(), config.Travel)
+		if err != nil {
+			return err
+		}
+
+		server := internal.NewServer(internal.ServerOptions{
+			InputPath: inputPath,
+			Ordering:  ordering,
+			Config:    config,
+			Travel:    travel,
+		})
+		if enableAPI {
+			server.Handle("/api/", internal.NewAPI(internal.APIOptions{
+				InputPath: inputPath,
+				BaseUrl:   config.BaseUrl(),
+				Travel:    travel,
+			}))
+		}
+		go server.Watch(ctx)
+
+		httpServer := &http.Server{
+			Addr:        addr,
+			Handler:     server,
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			<-ctx.Done()
+			httpServer.Shutdown(context.Background())
+		}()
+
+		slog.Info("serving", "input", inputPath, "url", fmt.Sprintf("http://%v/", addr))
+		err = httpServer.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("input", "output.json", "The input file to load into the view")
+	serveCmd.Flags().String("addr", "localhost:8080", "The address to listen on")
+	serveCmd.Flags().Bool("api", false, "Serve the JSON API under /api/")
+	addOrderingFlags(serveCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Statistics from the openings history",
+	Long: fmt.Sprintf(`Commands reporting on the openings recorded in %v.
+
+Every load, search and watch poll appends the openings of the activities it
+finds to the history.`, internal.DefaultHistoryPath),
+}
+
+// statsFillCmd represents the stats fill command
+var statsFillCmd = &cobra.Command{
+	Use:   "fill",
+	Short: "Show how fast activities sold out",
+	Long: `Show how long each activity took to sell out, from the first time it was
+recorded with openings to the first time it was recorded full, the fastest
+first. Activities that never sold out are listed last.
+
+With --by level or --by center the activities are grouped, showing how many
+sold out and the median and fastest times to fill. The level is the program
+and level parsed from an activity name, see the names section of the config.
+
+The times are only as precise as the history: load or watch often during
+registration for useful numbers.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, err := cmd.Flags().GetString("by")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{models.FillByActivity, models.FillByLevel, models.FillByCenter}, by) {
+			return usageErrorf("unexpected grouping %v, expected activity, level or center", by)
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains([]string{internal.FormatTable, internal.FormatJSON, internal.FormatCSV}, format) {
+			return usageErrorf("unexpected format %v, expected table, json or csv", format)
+		}
+
+		seasonId, err := getStatsSeason(cmd, "for-season")
+		if err != nil {
+			return err
+		}
+
+		historyPath, err := cmd.Flags().GetString("history")
+		if err != nil {
+			return err
+		}
+
+		fills, err := readFills(historyPath, seasonId)
+		if err != nil {
+			return err
+		}
+		if by == models.FillByActivity {
+			return writeActivityFills(fills, format)
+		}
+
+		groups, err := models.GroupFill(fills, by)
+		if err != nil {
+			return err
+		}
+		return writeFillGroups(groups, by, format)
+	},
+}
+
+// getStatsSeason resolves the season flag with the cached filters, so that it
+// may be a name. Past seasons may no longer be listed, so a season that isn't
+// found is used as an ID.
+func getStatsSeason(cmd *cobra.Command, flag string) (string, error) {
+	season, err := cmd.Flags().GetString(flag)
+	if err != nil || season == "" {
+		return "", err
+	}
+
+	filters, err := internal.ReadCachedFilters(config.BaseUrl())
+	if err != nil {
+		slog.Debug("no cached filters, using the season as an ID", "error", err)
+		return season, nil
+	}
+
+	c, err := models.Find("season", filters.Seasons, season)
+	if err != nil {
+		slog.Debug("using the season as an ID", "error", err)
+		return season, nil
+	}
+	return c.Id, nil
+}
+
+func readFills(historyPath string, seasonId string) ([]*models.ActivityFill, error) {
+	snapshots, err := internal.ReadSnapshots(historyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no openings recorded in %v yet, run gojoin load first", historyPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := config.NameRules()
+	if err != nil {
+		return nil, err
+	}
+
+	return models.FillStats(snapshots, seasonId, rules), nil
+}
+
+func formatTimeToFill(f *models.ActivityFill) string {
+	d, ok := f.TimeToFill()
+	if !ok {
+		return "-"
+	}
+	return models.FormatDuration(d)
+}
+
+func writeActivityFills(fills []*models.ActivityFill, format string) error {
+	if format == internal.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(fills)
+	}
+
+	table := internal.Table{Header: []string{"NAME", "NUMBER", "CENTER", "OPENINGS", "FIRST SEEN", "TIME TO FILL"}}
+	for _, f := range fills {
+		table.Append(f.Name, f.Number, f.CenterName, strconv.Itoa(f.Initial), f.FirstSeen.Local().Format(time.DateTime), formatTimeToFill(f))
+	}
+	return table.Write(os.Stdout, format)
+}
+
+func writeFillGroups(groups []*models.FillGroup, by string, format string) error {
+	if format == internal.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(groups)
+	}
+
+	table := internal.Table{Header: []string{"LEVEL", "ACTIVITIES", "SOLD OUT", "MEDIAN", "FASTEST"}}
+	if by == models.FillByCenter {
+		table.Header[0] = "CENTER"
+	}
+	for _, g := range groups {
+		median, fastest := "-", "-"
+		if g.SoldOut > 0 {
+			median = models.FormatDuration(g.Median)
+			fastest = models.FormatDuration(g.Fastest)
+		}
+		table.Append(g.Name, strconv.Itoa(g.Activities), strconv.Itoa(g.SoldOut), median, fastest)
+	}
+	return table.Write(os.Stdout, format)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsFillCmd)
+
+	statsFillCmd.Flags().String("by", models.FillByActivity, "Group the activities by activity, level or center")
+	// not --season, which the profiles set to the season being planned
+	statsFillCmd.Flags().String("for-season", "", "Only the activities of the season, by ID or name")
+	statsFillCmd.Flags().String("history", internal.DefaultHistoryPath, "The openings history file")
+	statsFillCmd.Flags().String("format", internal.FormatTable, "The output format: table, json or csv")
+	statsFillCmd.RegisterFlagCompletionFunc("by", cobra.FixedCompletions([]string{models.FillByActivity, models.FillByLevel, models.FillByCenter}, cobra.ShellCompDirectiveNoFileComp))
+	statsFillCmd.RegisterFlagCompletionFunc("for-season", completeCriteria("seasons"))
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the plan file for problems",
+	Long: `Check that the plan file parses and look for missing IDs, unparseable time
+ranges, unknown weekdays, duplicate activities, activities without persons,
+invalid selections and centers without activities. Problems are reported with
+their line and column in the file.
+
+With --fix, the problems that can be repaired without losing information are
+fixed and the plan is written back: duplicates within a center are merged,
+weekdays are abbreviated, invalid selections are dropped, centers without
+activities are removed and an older schema version is migrated. Activities
+without persons are only reported. A file that doesn't parse is never
+rewritten.
+
+The command exits with code 4 when problems remain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		fix, err := cmd.Flags().GetBool("fix")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "text" && format != internal.FormatJSON {
+			return usageErrorf("unexpected format %v, expected text or json", format)
+		}
+
+		validation, err := internal.ValidatePlan(inputPath)
+		if err != nil {
+			return err
+		}
+
+		if fix {
+			if validation.Plan == nil {
+				err = writeIssues(validation, format)
+				if err != nil {
+					return err
+				}
+				return &internal.PlanError{Path: inputPath, Err: fmt.Errorf("can't be fixed because it doesn't parse")}
+			}
+
+			var fixed []*models.Problem
+			err = internal.UpdatePlan(inputPath, func(plan *models.Plan) error {
+				fixed = plan.Repair()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, problem := range fixed {
+				slog.Info("fixed", "path", problem.Path, "problem", problem.Message)
+			}
+
+			validation, err = internal.ValidatePlan(inputPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = writeIssues(validation, format)
+		if err != nil {
+			return err
+		}
+
+		if len(validation.Issues) > 0 {
+			return &internal.PlanError{Path: inputPath, Err: fmt.Errorf("%d problems found", len(validation.Issues))}
+		}
+		return nil
+	},
+}
+
+func writeIssues(validation *internal.Validation, format string) error {
+	if format == internal.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(validation.Issues)
+	}
+
+	for _, issue := range validation.Issues {
+		fmt.Printf("%v:%v\n", validation.Path, issue)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().String("input", "output.json", "The plan file to check")
+	validateCmd.Flags().Bool("fix", false, "Repair the problems that can be fixed safely and write the plan back")
+	validateCmd.Flags().String("format", "text", "The output format: text or json")
+}
@@ -1,12 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-	"html/template"
+	"bytes"
+	"io"
 	"os"
-	"path"
+	"slices"
 
+	"github.com/snocorp/gojoin/internal"
 	"github.com/snocorp/gojoin/models"
 	"github.com/spf13/cobra"
 )
@@ -14,78 +14,252 @@ import (
 // viewCmd represents the view command
 var viewCmd = &cobra.Command{
 	Use:   "view",
-	Short: "Output the view to HTML",
-	Long:  `Render an HTML template using the loaded data.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "Output the view to HTML, SVG, PNG or an agenda",
+	Long: `Render the loaded data as an HTML page, an SVG document, a PNG image or
+an agenda listing each day's activities in order.
+
+The HTML page shades the times at which no person of the plan is available, or
+no driver is, following the availability section of the config, and marks the
+selected and shortlisted activities that conflict.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		inputPath, err := cmd.Flags().GetString("input")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
 
-		inputBytes, err := os.ReadFile(inputPath)
+		format, err := cmd.Flags().GetString("format")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
+		}
+		if !slices.Contains([]string{"html", "svg", "png", "agenda"}, format) {
+			return usageErrorf("unknown format %v", format)
 		}
 
-		var plan models.Plan
-		err = json.Unmarshal(inputBytes, &plan)
+		outputPath, err := cmd.Flags().GetString("output")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
 
-		centerMap := map[string]*models.CenterWeek{}
-		for _, p := range plan.Plans {
-			for _, cw := range p.CenterWeeks {
-				centerWeek, ok := centerMap[cw.CenterId]
-				if !ok {
-					centerWeek = &models.CenterWeek{
-						CenterId:   cw.CenterId,
-						CenterName: cw.CenterName,
-						Events:     []*models.Activity{},
-					}
-					centerMap[cw.CenterId] = centerWeek
-				}
-
-				centerWeek.Events = append(centerWeek.Events, cw.Events...)
-			}
+		canvasOptions, err := getCanvasOptions(cmd)
+		if err != nil {
+			return err
 		}
 
-		centerPlan := &models.CenterPlan{Plans: []*models.CenterWeek{}}
-		for _, cw := range centerMap {
-			centerPlan.Plans = append(centerPlan.Plans, cw)
+		ordering, err := getOrdering(cmd)
+		if err != nil {
+			return err
 		}
 
-		view, err := models.NewView(centerPlan)
+		plan, err := internal.ReadPlan(inputPath)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
+		}
+
+		var fill []*models.FillBar
+		if format == "html" {
+			fill, err = getFillChart(cmd)
+			if err != nil {
+				return err
+			}
 		}
 
-		funcMap := template.FuncMap{
-			"css": func(s string) template.CSS {
-				return template.CSS(s)
-			},
+		// rendered in memory so that a failure leaves the output untouched
+		var out bytes.Buffer
+		if format == "agenda" {
+			err = renderAgenda(cmd, &out, plan, ordering)
+			if err != nil {
+				return err
+			}
+			return writeView(out.Bytes(), outputPath)
 		}
 
-		filename := "./templates/week.html.gotmpl"
-		name := path.Base(filename)
-		tmpl, err := template.New(name).Funcs(funcMap).ParseFiles(filename)
+		view, err := models.NewView(plan.CenterPlan(), ordering)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
+		}
+		view.Fill = fill
+		if format == "html" {
+			travel, err := internal.LoadTravelTimes(config.BaseUrl(), config.Travel)
+			if err != nil {
+				return err
+			}
+			err = internal.DecorateView(view, plan, config, travel)
+			if err != nil {
+				return err
+			}
 		}
 
-		err = tmpl.Execute(os.Stdout, view)
+		switch format {
+		case "html":
+			err = internal.RenderHTML(&out, view, internal.DefaultWeekTemplate)
+		case "svg":
+			err = internal.RenderSVG(&out, view, canvasOptions)
+		case "png":
+			err = internal.RenderPNG(&out, view, canvasOptions)
+		}
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
+
+		return writeView(out.Bytes(), outputPath)
 	},
 }
 
+// writeView writes the rendered view to the output file, or to stdout when
+// there is none.
+func writeView(data []byte, outputPath string) error {
+	if outputPath == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return internal.WriteOutput(outputPath, data)
+}
+
+// getFillChart returns the time to fill chart asked for by --fill-by, if
+// any.
+func getFillChart(cmd *cobra.Command) ([]*models.FillBar, error) {
+	by, err := cmd.Flags().GetString("fill-by")
+	if err != nil || by == "" {
+		return nil, err
+	}
+	if !slices.Contains([]string{models.FillByActivity, models.FillByLevel, models.FillByCenter}, by) {
+		return nil, usageErrorf("unexpected grouping %v, expected activity, level or center", by)
+	}
+
+	seasonId, err := getStatsSeason(cmd, "fill-season")
+	if err != nil {
+		return nil, err
+	}
+
+	fills, err := readFills(internal.DefaultHistoryPath, seasonId)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := models.GroupFill(fills, by)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.FillChart(groups), nil
+}
+
+func renderAgenda(cmd *cobra.Command, out io.Writer, plan *models.Plan, ordering models.Ordering) error {
+	style, err := cmd.Flags().GetString("agenda-style")
+	if err != nil {
+		return err
+	}
+
+	groupBy, err := cmd.Flags().GetString("group-by")
+	if err != nil {
+		return err
+	}
+
+	agenda, err := models.NewAgenda(plan, groupBy, ordering)
+	if err != nil {
+		return err
+	}
+
+	return internal.RenderAgenda(out, agenda, style)
+}
+
+func getOrdering(cmd *cobra.Command) (models.Ordering, error) {
+	centerOrder, err := cmd.Flags().GetString("center-order")
+	if err != nil {
+		return models.Ordering{}, err
+	}
+
+	personOrder, err := cmd.Flags().GetString("person-order")
+	if err != nil {
+		return models.Ordering{}, err
+	}
+
+	activityOrder, err := cmd.Flags().GetString("activity-order")
+	if err != nil {
+		return models.Ordering{}, err
+	}
+
+	colourBy, err := cmd.Flags().GetString("colour-by")
+	if err != nil {
+		return models.Ordering{}, err
+	}
+
+	names, err := config.NameRules()
+	if err != nil {
+		return models.Ordering{}, err
+	}
+
+	ordering := models.Ordering{
+		Centers:    centerOrder,
+		Persons:    personOrder,
+		Activities: activityOrder,
+		ColourBy:   colourBy,
+
+		UserCenters: config.Order.Centers,
+		UserPersons: config.Order.Persons,
+		Names:       names,
+	}
+	err = ordering.Validate()
+	if err != nil {
+		return models.Ordering{}, usageErr(err)
+	}
+
+	if centerOrder == models.OrderDistance {
+		if config.Home == nil {
+			return models.Ordering{}, usageErrorf("ordering centers by distance needs a home location in the config")
+		}
+		ordering.CenterDistances, err = config.CenterDistances()
+		if err != nil {
+			return models.Ordering{}, err
+		}
+	}
+
+	return ordering, nil
+}
+
+func addOrderingFlags(cmd *cobra.Command) {
+	cmd.Flags().String("center-order", models.OrderUser, "The order of centers: user, name, id or distance, user being the order section of the config then the plan order")
+	cmd.Flags().String("person-order", models.OrderUser, "The order of persons: user or name, user being the order section of the config then the plan order")
+	cmd.Flags().String("activity-order", models.OrderName, "The order of activities starting at the same time: user, name or id")
+	cmd.Flags().String("colour-by", models.ColourByName, "Colour the activities by name or by level")
+	cmd.RegisterFlagCompletionFunc("colour-by", cobra.FixedCompletions([]string{models.ColourByName, models.ColourByLevel}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func getCanvasOptions(cmd *cobra.Command) (internal.CanvasOptions, error) {
+	width, err := cmd.Flags().GetInt("width")
+	if err != nil {
+		return internal.CanvasOptions{}, err
+	}
+
+	height, err := cmd.Flags().GetInt("height")
+	if err != nil {
+		return internal.CanvasOptions{}, err
+	}
+
+	fontFamily, err := cmd.Flags().GetString("font-family")
+	if err != nil {
+		return internal.CanvasOptions{}, err
+	}
+
+	fontSize, err := cmd.Flags().GetFloat64("font-size")
+	if err != nil {
+		return internal.CanvasOptions{}, err
+	}
+
+	fontPath, err := cmd.Flags().GetString("font")
+	if err != nil {
+		return internal.CanvasOptions{}, err
+	}
+
+	return internal.CanvasOptions{
+		Width:      width,
+		Height:     height,
+		FontFamily: fontFamily,
+		FontSize:   fontSize,
+		FontPath:   fontPath,
+	}, nil
+}
+
 func init() {
 	rootCmd.AddCommand(viewCmd)
 
@@ -98,4 +272,22 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly:
 	viewCmd.Flags().String("input", "", "The input file to load into the view")
+	viewCmd.Flags().String("format", "html", "The output format: html, svg, png or agenda")
+	viewCmd.Flags().String("output", "", "The file to write the view to, defaults to stdout")
+
+	viewCmd.Flags().String("agenda-style", internal.AgendaText, "The agenda style: text, markdown or html")
+	viewCmd.Flags().String("group-by", models.AgendaByPerson, "Group the agenda by person, center or level")
+
+	addOrderingFlags(viewCmd)
+
+	viewCmd.Flags().String("fill-by", "", "Add a chart of how fast activities sold out by activity, level or center (html), see gojoin stats fill")
+	viewCmd.Flags().String("fill-season", "", "Only chart the activities of the season, by ID or name")
+	viewCmd.RegisterFlagCompletionFunc("fill-by", cobra.FixedCompletions([]string{models.FillByActivity, models.FillByLevel, models.FillByCenter}, cobra.ShellCompDirectiveNoFileComp))
+	viewCmd.RegisterFlagCompletionFunc("fill-season", completeCriteria("seasons"))
+
+	viewCmd.Flags().Int("width", internal.DefaultCanvasWidth, "The width of the image in pixels (svg, png)")
+	viewCmd.Flags().Int("height", internal.DefaultCanvasHeight, "The height of the image in pixels (svg, png)")
+	viewCmd.Flags().String("font-family", internal.DefaultFontFamily, "The font family used in the SVG")
+	viewCmd.Flags().Float64("font-size", internal.DefaultFontSize, "The font size in pixels (svg, png)")
+	viewCmd.Flags().String("font", "", "A TrueType or OpenType font file used to render the PNG")
 }
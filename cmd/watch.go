@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/snocorp/gojoin/internal"
+	"github.com/snocorp/gojoin/models"
+	"github.com/spf13/cobra"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [activity id...]",
+	Short: "Watch activities for openings",
+	Long: `Poll ActiveNet on an interval and notify when a watched activity goes from
+full to available.
+
+The activities are given by ID, looked up in the plan file, or by --query,
+the name of a config profile whose search is watched. The openings seen are
+kept in --state between runs, so that an opening that happens while the
+watcher isn't running is still reported.
+
+Notifications are printed to stdout and, when configured, sent to a shell
+command (--exec), a webhook receiving JSON (--webhook) and by mail using the
+notify.smtp section of the config. The command receives the opening as JSON on
+stdin and in GOJOIN_ACTIVITY_ID, GOJOIN_ACTIVITY_NAME, GOJOIN_ACTIVITY_NUMBER,
+GOJOIN_ACTIVITY_URL, GOJOIN_OPENINGS and GOJOIN_PREVIOUS_OPENINGS.
+
+The watcher runs until interrupted, or polls once with --once.`,
+	ValidArgsFunction: completeActivities("input"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		queries, err := cmd.Flags().GetStringSlice("query")
+		if err != nil {
+			return err
+		}
+
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+
+		once, err := cmd.Flags().GetBool("once")
+		if err != nil {
+			return err
+		}
+
+		statePath, err := cmd.Flags().GetString("state")
+		if err != nil {
+			return err
+		}
+
+		notifiers, err := getNotifiers(cmd)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 && len(queries) == 0 {
+			return usageErrorf("give the IDs of activities to watch or --query")
+		}
+
+		targets, err := getQueryTargets(queries)
+		if err != nil {
+			return err
+		}
+
+		idTargets, err := getActivityTargets(inputPath, args)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, idTargets...)
+
+		watcher, err := internal.NewWatcher(internal.WatchOptions{
+			BaseUrl:     config.BaseUrl(),
+			Targets:     targets,
+			Interval:    interval,
+			StatePath:   statePath,
+			HistoryPath: internal.DefaultHistoryPath,
+			Notifiers:   notifiers,
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if once {
+			_, err = watcher.Poll(ctx)
+			return err
+		}
+
+		return watcher.Run(ctx)
+	},
+}
+
+func getNotifiers(cmd *cobra.Command) ([]internal.Notifier, error) {
+	command, err := cmd.Flags().GetString("exec")
+	if err != nil {
+		return nil, err
+	}
+	if command == "" {
+		command = config.Notify.Command
+	}
+
+	webhook, err := cmd.Flags().GetString("webhook")
+	if err != nil {
+		return nil, err
+	}
+	if webhook == "" {
+		webhook = config.Notify.Webhook
+	}
+
+	notifiers := []internal.Notifier{&internal.StdoutNotifier{W: os.Stdout}}
+	if command != "" {
+		notifiers = append(notifiers, &internal.CommandNotifier{Command: command})
+	}
+	if webhook != "" {
+		notifiers = append(notifiers, &internal.WebhookNotifier{Url: webhook})
+	}
+	if smtp := config.Notify.SMTP; smtp != nil {
+		if smtp.Host == "" || smtp.From == "" || len(smtp.To) == 0 {
+			return nil, usageErrorf("notify.smtp needs a host, from and to")
+		}
+		notifiers = append(notifiers, &internal.SMTPNotifier{Config: *smtp})
+	}
+
+	return notifiers, nil
+}
+
+// getQueryTargets watches the search of each named profile.
+func getQueryTargets(queries []string) ([]*internal.WatchTarget, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	filters, err := internal.GetFilters(internal.GetFiltersOptions{
+		BaseUrl: config.BaseUrl(),
+		NoCache: noCache,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []*internal.WatchTarget{}
+	for _, name := range queries {
+		p, err := config.Profile(name)
+		if err != nil {
+			return nil, usageErr(err)
+		}
+
+		options, err := resolveSearchOptions(filters, p, false)
+		if err != nil {
+			return nil, fmt.Errorf("query %v: %w", name, err)
+		}
+
+		targets = append(targets, &internal.WatchTarget{
+			Name:       name,
+			Request:    options.request(),
+			CenterName: options.center.Description,
+		})
+	}
+
+	return targets, nil
+}
+
+// getActivityTargets watches activities of the plan by searching their center
+// for their number.
+func getActivityTargets(inputPath string, ids []string) ([]*internal.WatchTarget, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	plan, err := internal.ReadPlan(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []*internal.WatchTarget{}
+	for _, arg := range ids {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, usageErrorf("unexpected activity ID %v", arg)
+		}
+
+		var target *internal.WatchTarget
+		for _, cw := range plan.Centers {
+			for _, e := range cw.Events {
+				if e.Id != id {
+					continue
+				}
+
+				keyword := e.Number
+				if keyword == "" {
+					keyword = e.Name
+				}
+				target = &internal.WatchTarget{
+					Name: fmt.Sprintf("activity %v", id),
+					Request: models.ActivityRequest{
+						SearchPattern: &models.ActivitySearchPattern{
+							CenterIds:       []string{cw.CenterId},
+							ActivityKeyword: keyword,
+						},
+					},
+					CenterName: cw.CenterName,
+					Ids:        []int{id},
+				}
+			}
+		}
+		if target == nil {
+			return nil, usageErrorf("activity %v isn't in %v", id, inputPath)
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().String("input", "output.json", "The plan file the activity IDs are looked up in")
+	watchCmd.Flags().StringSlice("query", nil, "The name of a config profile whose search is watched, may be repeated")
+	watchCmd.Flags().Duration("interval", internal.DefaultWatchInterval, "How often to poll")
+	watchCmd.Flags().Bool("once", false, "Poll once and exit")
+	watchCmd.Flags().String("state", internal.DefaultWatchStatePath, "The file the openings seen are kept in between runs")
+	watchCmd.Flags().String("exec", "", "A shell command run for each opening, notify.command in the config")
+	watchCmd.Flags().String("webhook", "", "A URL each opening is posted to as JSON, notify.webhook in the config")
+	watchCmd.RegisterFlagCompletionFunc("query", completeProfiles)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,8 +14,11 @@ import (
 	"github.com/snocorp/gojoin/models"
 )
 
+const DefaultBaseUrl = "https://anc.ca.apm.activecommunities.com/ottawa"
+
 type GetActivitiesOptions struct {
-	Verbose bool
+	// BaseUrl is the ActiveNet site to search, DefaultBaseUrl when empty.
+	BaseUrl string
 }
 
 // {"order_by":"Name","page_number":2,"total_records_per_page":20}
@@ -30,11 +34,16 @@ func GetActivities(request models.ActivityRequest, options GetActivitiesOptions)
 		return
 	}
 
+	baseUrl := options.BaseUrl
+	if baseUrl == "" {
+		baseUrl = DefaultBaseUrl
+	}
+
 	morePages := true
 	pageNumber := 1
 	var result []*models.Activity
 	for morePages {
-		result, morePages, err = getActivities(requestBytes, pageNumber)
+		result, morePages, err = getActivities(baseUrl, requestBytes, pageNumber)
 		if err != nil {
 			return activities, err
 		}
@@ -46,18 +55,34 @@ func GetActivities(request models.ActivityRequest, options GetActivitiesOptions)
 	return
 }
 
-func getActivities(requestBytes []byte, page int) ([]*models.Activity, bool, error) {
+func getActivities(baseUrl string, requestBytes []byte, page int) ([]*models.Activity, bool, error) {
+	url, body, err := postActivities(baseUrl, requestBytes, page)
+	if err != nil {
+		return []*models.Activity{}, false, err
+	}
+
+	var searchResponse models.ActivitySearchResponse
+	err = json.Unmarshal(body, &searchResponse)
+	if err != nil {
+		slog.Debug("unexpected response", "url", url, "body", string(body))
+		return []*models.Activity{}, false, &RequestError{url, err}
+	}
+
+	morePages := searchResponse.Headers.PageInfo.PageNumber < searchResponse.Headers.PageInfo.TotalPages
+	return searchResponse.Body.ActivityItems, morePages, nil
+}
+
+// postActivities requests a page of an activity search and returns the URL
+// and the body of the response.
+func postActivities(baseUrl string, requestBytes []byte, page int) (string, []byte, error) {
 	client := http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	req, err := http.NewRequest(
-		"POST",
-		"https://anc.ca.apm.activecommunities.com/ottawa/rest/activities/list?locale=en-US",
-		bytes.NewReader(requestBytes),
-	)
+	url := baseUrl + "/rest/activities/list?locale=en-US"
+	req, err := http.NewRequest("POST", url, bytes.NewReader(requestBytes))
 	if err != nil {
-		return []*models.Activity{}, false, err
+		return url, nil, err
 	}
 
 	pageInfo := PageInfo{
@@ -67,7 +92,7 @@ func getActivities(requestBytes []byte, page int) ([]*models.Activity, bool, err
 	}
 	pageInfoJson, err := json.Marshal(pageInfo)
 	if err != nil {
-		return []*models.Activity{}, false, err
+		return url, nil, err
 	}
 
 	req.Header.Add("Page_info", string(pageInfoJson))
@@ -77,27 +102,20 @@ func getActivities(requestBytes []byte, page int) ([]*models.Activity, bool, err
 	req.Header.Add("origin", "https://anc.ca.apm.activecommunities.com")
 	req.Header.Add("x-csrf-token", uuid.NewString())
 
+	slog.Debug("requesting activities", "url", url, "page", page)
 	resp, err := client.Do(req)
 	if err != nil {
-		return []*models.Activity{}, false, err
+		return url, nil, &RequestError{url, err}
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return []*models.Activity{}, false, fmt.Errorf("unexpected status %v", resp.StatusCode)
+		return url, nil, &RequestError{url, fmt.Errorf("unexpected status %v", resp.StatusCode)}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return []*models.Activity{}, false, err
-	}
-
-	var searchResponse models.ActivitySearchResponse
-	err = json.Unmarshal(body, &searchResponse)
-	if err != nil {
-		fmt.Println(string(body))
-		return []*models.Activity{}, false, err
+		return url, nil, &RequestError{url, err}
 	}
 
-	morePages := searchResponse.Headers.PageInfo.PageNumber < searchResponse.Headers.PageInfo.TotalPages
-	return searchResponse.Body.ActivityItems, morePages, nil
+	return url, body, nil
 }
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+const (
+	AgendaText     = "text"
+	AgendaMarkdown = "markdown"
+	AgendaHTML     = "html"
+
+	DefaultAgendaTemplate = "./templates/agenda.html.gotmpl"
+)
+
+// RenderAgenda writes the agenda in the given style: text, markdown or html.
+func RenderAgenda(w io.Writer, agenda *models.Agenda, style string) error {
+	switch style {
+	case "", AgendaText:
+		return renderAgendaText(w, agenda)
+	case AgendaMarkdown, "md":
+		return renderAgendaMarkdown(w, agenda)
+	case AgendaHTML:
+		return renderAgendaHTML(w, agenda, DefaultAgendaTemplate)
+	}
+
+	return fmt.Errorf("unknown agenda style %v", style)
+}
+
+// agendaWhere describes the item by whatever the agenda isn't grouped by.
+func agendaWhere(agenda *models.Agenda, item *models.AgendaItem) string {
+	switch agenda.GroupBy {
+	case models.AgendaByCenter:
+		return strings.Join(item.Persons, ", ")
+	case models.AgendaByLevel:
+		return strings.Join(append([]string{item.CenterName}, item.Persons...), ", ")
+	}
+	return item.CenterName
+}
+
+func renderAgendaText(w io.Writer, agenda *models.Agenda) error {
+	b := bufio.NewWriter(w)
+	for i, g := range agenda.Groups {
+		if i > 0 {
+			fmt.Fprintln(b)
+		}
+		fmt.Fprintln(b, g.Name)
+		fmt.Fprintln(b, strings.Repeat("=", len(g.Name)))
+		for _, d := range g.Days {
+			fmt.Fprintln(b)
+			fmt.Fprintln(b, d.Day.Name)
+			for _, item := range d.Items {
+				a := item.Activity
+				fmt.Fprintf(b, "  %-20s %-20s %s (#%s)\n", a.TimeRange, agendaWhere(agenda, item), a.Name, a.Number)
+				if a.DetailUrl != "" {
+					fmt.Fprintf(b, "  %-20s %-20s %s\n", "", "", a.DetailUrl)
+				}
+			}
+		}
+	}
+
+	return b.Flush()
+}
+
+func renderAgendaMarkdown(w io.Writer, agenda *models.Agenda) error {
+	b := bufio.NewWriter(w)
+	for i, g := range agenda.Groups {
+		if i > 0 {
+			fmt.Fprintln(b)
+		}
+		fmt.Fprintf(b, "## %s\n", g.Name)
+		for _, d := range g.Days {
+			fmt.Fprintf(b, "\n### %s\n\n", d.Day.Name)
+			for _, item := range d.Items {
+				a := item.Activity
+				name := a.Name
+				if a.DetailUrl != "" {
+					name = fmt.Sprintf("[%s](%s)", a.Name, a.DetailUrl)
+				}
+				fmt.Fprintf(b, "- **%s** %s (#%s), %s\n", a.TimeRange, name, a.Number, agendaWhere(agenda, item))
+			}
+		}
+	}
+
+	return b.Flush()
+}
+
+func renderAgendaHTML(w io.Writer, agenda *models.Agenda, filename string) error {
+	funcMap := templateFuncs()
+	funcMap["where"] = func(item *models.AgendaItem) string {
+		return agendaWhere(agenda, item)
+	}
+
+	name := path.Base(filename)
+	tmpl, err := template.New(name).Funcs(funcMap).ParseFiles(filename)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, agenda)
+}
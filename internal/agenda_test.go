@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+func TestRenderAgendaGolden(t *testing.T) {
+	tests := []struct {
+		groupBy string
+		style   string
+		golden  string
+	}{
+		{models.AgendaByPerson, AgendaText, "agenda.person.golden.txt"},
+		{models.AgendaByCenter, AgendaText, "agenda.center.golden.txt"},
+		{models.AgendaByLevel, AgendaText, "agenda.level.golden.txt"},
+		{models.AgendaByPerson, AgendaMarkdown, "agenda.person.golden.md"},
+		{models.AgendaByPerson, AgendaHTML, "agenda.person.golden.html"},
+	}
+
+	for _, test := range tests {
+		plan, err := ReadPlan("testdata/plan.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		agenda, err := models.NewAgenda(plan, test.groupBy, models.Ordering{Activities: models.OrderName})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if test.style == AgendaHTML {
+			err = renderAgendaHTML(&buf, agenda, "../templates/agenda.html.gotmpl")
+		} else {
+			err = RenderAgenda(&buf, agenda, test.style)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkGolden(t, test.golden, buf.Bytes())
+	}
+}
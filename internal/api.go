@@ -0,0 +1,325 @@
+package internal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+type APIOptions struct {
+	InputPath string
+	// BaseUrl is the ActiveNet site used for searches, DefaultBaseUrl when
+	// empty.
+	BaseUrl string
+	// Travel is used to find activities too far apart, none when nil.
+	Travel *models.TravelTimes
+}
+
+// API is a JSON HTTP API over a plan file. Requests that modify the plan are
+// serialised so that concurrent writes don't lose each other's changes.
+type API struct {
+	options APIOptions
+	mux     *http.ServeMux
+	mu      sync.RWMutex
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type apiPerson struct {
+	Person     string   `json:"person"`
+	CenterIds  []string `json:"center_ids"`
+	Activities int      `json:"activities"`
+}
+
+type apiCenter struct {
+	CenterId   string   `json:"center_id"`
+	CenterName string   `json:"center_name"`
+	Persons    []string `json:"persons"`
+}
+
+type apiActivity struct {
+	CenterId   string           `json:"center_id"`
+	CenterName string           `json:"center_name"`
+	Activity   *models.Activity `json:"activity"`
+}
+
+type apiSelection struct {
+	Selection string `json:"selection"`
+}
+
+func NewAPI(options APIOptions) *API {
+	api := &API{
+		options: options,
+		mux:     http.NewServeMux(),
+	}
+
+	api.mux.HandleFunc("GET /api/openapi.yaml", api.handleOpenAPI)
+	api.mux.HandleFunc("GET /api/plan.schema.json", api.handlePlanSchema)
+	api.mux.HandleFunc("GET /api/persons", api.handlePersons)
+	api.mux.HandleFunc("GET /api/centers", api.handleCenters)
+	api.mux.HandleFunc("GET /api/activities", api.handleActivities)
+	api.mux.HandleFunc("GET /api/search", api.handleSearch)
+	api.mux.HandleFunc("PUT /api/persons/{person}/activities/{id}/selection", api.handleSelection)
+	api.mux.HandleFunc("GET /api/conflicts", api.handleConflicts)
+
+	return api
+}
+
+func (api *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+func (api *API) readPlan(w http.ResponseWriter) (*models.Plan, bool) {
+	plan, err := ReadPlan(api.options.InputPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return plan, true
+}
+
+func (api *API) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+func (api *API) handlePlanSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(PlanSchema)
+}
+
+func (api *API) handlePersons(w http.ResponseWriter, r *http.Request) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	plan, ok := api.readPlan(w)
+	if !ok {
+		return
+	}
+
+	persons := []apiPerson{}
+	for _, p := range plan.Persons() {
+		person := apiPerson{Person: p, CenterIds: []string{}}
+		for _, cw := range plan.ForPerson(p).Centers {
+			person.CenterIds = append(person.CenterIds, cw.CenterId)
+			person.Activities += len(cw.Events)
+		}
+		persons = append(persons, person)
+	}
+
+	writeJSON(w, http.StatusOK, persons)
+}
+
+func (api *API) handleCenters(w http.ResponseWriter, r *http.Request) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	plan, ok := api.readPlan(w)
+	if !ok {
+		return
+	}
+
+	centers := []*apiCenter{}
+	for _, cw := range plan.Centers {
+		center := &apiCenter{CenterId: cw.CenterId, CenterName: cw.CenterName, Persons: []string{}}
+		for _, e := range cw.Events {
+			for _, p := range e.Persons {
+				if !slices.Contains(center.Persons, p) {
+					center.Persons = append(center.Persons, p)
+				}
+			}
+		}
+		centers = append(centers, center)
+	}
+
+	writeJSON(w, http.StatusOK, centers)
+}
+
+func (api *API) handleActivities(w http.ResponseWriter, r *http.Request) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	plan, ok := api.readPlan(w)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	person := query.Get("person")
+	centerId := query.Get("center")
+	selection := query.Get("selection")
+
+	if person != "" {
+		plan = plan.ForPerson(person)
+	}
+
+	activities := []apiActivity{}
+	for _, cw := range plan.Centers {
+		if centerId != "" && cw.CenterId != centerId {
+			continue
+		}
+		for _, e := range cw.Events {
+			if selection != "" && !hasSelection(e, person, selection) {
+				continue
+			}
+			activities = append(activities, apiActivity{
+				CenterId:   cw.CenterId,
+				CenterName: cw.CenterName,
+				Activity:   e,
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, activities)
+}
+
+// hasSelection reports whether the activity has the selection for the person,
+// or for anybody when person is empty.
+func hasSelection(a *models.Activity, person string, selection string) bool {
+	if person != "" {
+		return string(a.SelectionFor(person)) == selection
+	}
+
+	for _, p := range a.Persons {
+		if string(a.SelectionFor(p)) == selection {
+			return true
+		}
+	}
+	return false
+}
+
+func splitQuery(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+func (api *API) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := models.ActivityRequest{
+		SearchPattern: &models.ActivitySearchPattern{
+			SeasonIds:           splitQuery(query["season"]),
+			CenterIds:           splitQuery(query["center"]),
+			ActivityCategoryIds: splitQuery(query["category"]),
+			ActivityKeyword:     query.Get("keyword"),
+		},
+	}
+
+	activities, err := GetActivities(req, GetActivitiesOptions{
+		BaseUrl: api.options.BaseUrl,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	if activities == nil {
+		activities = []*models.Activity{}
+	}
+	writeJSON(w, http.StatusOK, activities)
+}
+
+func (api *API) handleSelection(w http.ResponseWriter, r *http.Request) {
+	person := r.PathValue("person")
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var body apiSelection
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	selection, err := models.ParseSelection(body.Selection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	notFound := fmt.Errorf("activity %v not found for %v", id, person)
+	var updated *models.Activity
+	err = UpdatePlan(api.options.InputPath, func(plan *models.Plan) error {
+		for _, cw := range plan.Centers {
+			for _, e := range cw.Events {
+				if e.Id == id && e.HasPerson(person) {
+					e.SetSelection(person, selection)
+					updated = e
+				}
+			}
+		}
+
+		if updated == nil {
+			return notFound
+		}
+		return nil
+	})
+	if errors.Is(err, notFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (api *API) handleConflicts(w http.ResponseWriter, r *http.Request) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	plan, ok := api.readPlan(w)
+	if !ok {
+		return
+	}
+
+	var selections []models.Selection
+	if r.URL.Query().Get("selected") == "true" {
+		selections = []models.Selection{models.Selected}
+	}
+	conflicts, err := models.FindConflicts(plan, selections, api.options.Travel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, conflicts)
+}
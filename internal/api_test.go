@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+func newFakeActiveNet(t *testing.T, pages [][]*models.Activity) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/rest/activities/list" {
+			t.Errorf("Unexpected request %v %v", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		var pageInfo PageInfo
+		err := json.Unmarshal([]byte(r.Header.Get("Page_info")), &pageInfo)
+		if err != nil {
+			t.Errorf("Invalid page info: %v", err)
+		}
+
+		var req models.ActivityRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("Invalid request body: %v", err)
+		}
+
+		json.NewEncoder(w).Encode(models.ActivitySearchResponse{
+			Headers: &models.ActivitySearchHeaders{
+				PageInfo: &models.ActivityPageInfo{PageNumber: pageInfo.PageNumber, TotalPages: len(pages)},
+			},
+			Body: &models.ActivitySearchBody{ActivityItems: pages[pageInfo.PageNumber-1]},
+		})
+	}))
+}
+
+func writeTestPlan(t *testing.T) string {
+	plan := &models.Plan{}
+	plan.AddActivities("Bob", "165", "Plant", []*models.Activity{
+		{Id: 1, Name: "Swim Kids 2", TimeRange: "9:45 AM - 10:15 AM", DayOfWeek: "Sun"},
+		{Id: 2, Name: "Swim Kids 3", TimeRange: "10:00 AM - 10:30 AM", DayOfWeek: "Sun"},
+		{Id: 3, Name: "Swim Kids 4", TimeRange: "10:30 AM - 11:00 AM", DayOfWeek: "Sun"},
+	})
+	plan.AddActivities("Ann", "384", "Pinecrest", []*models.Activity{
+		{Id: 4, Name: "Lifesaving", TimeRange: "4:00 PM - 5:00 PM", DayOfWeek: "Wed"},
+	})
+	plan.AddActivities("Ann", "165", "Plant", []*models.Activity{
+		{Id: 3, Name: "Swim Kids 4", TimeRange: "10:30 AM - 11:00 AM", DayOfWeek: "Sun"},
+	})
+
+	filename := filepath.Join(t.TempDir(), "plan.json")
+	err := WritePlan(filename, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+func doRequest(t *testing.T, handler http.Handler, method, target, body string, result any) int {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if result != nil {
+		err := json.Unmarshal(rec.Body.Bytes(), result)
+		if err != nil {
+			t.Fatalf("Unable to parse response to %v %v: %v", method, target, rec.Body.String())
+		}
+	}
+
+	return rec.Code
+}
+
+func TestAPIPersonsAndCenters(t *testing.T) {
+	api := NewAPI(APIOptions{InputPath: writeTestPlan(t)})
+
+	var persons []apiPerson
+	status := doRequest(t, api, "GET", "/api/persons", "", &persons)
+	if status != http.StatusOK || len(persons) != 2 {
+		t.Fatalf("Expected 2 persons but got %d %v", status, persons)
+	}
+	if persons[1].Person != "Ann" || persons[1].Activities != 2 {
+		t.Errorf("Expected Ann with 2 activities but got %v", persons[1])
+	}
+	if persons[0].Person != "Bob" || persons[0].Activities != 3 {
+		t.Errorf("Expected Bob with 3 activities but got %v", persons[0])
+	}
+
+	var centers []apiCenter
+	doRequest(t, api, "GET", "/api/centers", "", &centers)
+	if len(centers) != 2 || centers[0].CenterId != "165" || len(centers[0].Persons) != 2 {
+		t.Errorf("Unexpected centers %v", centers)
+	}
+
+	var activities []apiActivity
+	doRequest(t, api, "GET", "/api/activities?center=165", "", &activities)
+	if len(activities) != 3 {
+		t.Errorf("Expected 3 activities but got %d", len(activities))
+	}
+
+	doRequest(t, api, "GET", "/api/activities?person=Ann", "", &activities)
+	if len(activities) != 2 {
+		t.Errorf("Expected 2 activities for Ann but got %d", len(activities))
+	}
+}
+
+func TestAPISearch(t *testing.T) {
+	activeNet := newFakeActiveNet(t, [][]*models.Activity{
+		{{Id: 10, Name: "Swim Kids 1"}},
+		{{Id: 11, Name: "Swim Kids 2"}},
+	})
+	defer activeNet.Close()
+
+	api := NewAPI(APIOptions{InputPath: writeTestPlan(t), BaseUrl: activeNet.URL})
+
+	var activities []*models.Activity
+	status := doRequest(t, api, "GET", "/api/search?season=46&center=165,384&keyword=swim", "", &activities)
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200 but got %d", status)
+	}
+	if len(activities) != 2 || activities[0].Id != 10 || activities[1].Id != 11 {
+		t.Errorf("Expected activities from both pages but got %v", activities)
+	}
+}
+
+func TestAPISearchFailure(t *testing.T) {
+	activeNet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer activeNet.Close()
+
+	api := NewAPI(APIOptions{InputPath: writeTestPlan(t), BaseUrl: activeNet.URL})
+
+	var apiErr apiError
+	status := doRequest(t, api, "GET", "/api/search?keyword=swim", "", &apiErr)
+	if status != http.StatusBadGateway || apiErr.Error == "" {
+		t.Errorf("Expected 502 with an error but got %d %v", status, apiErr)
+	}
+}
+
+func TestAPISelection(t *testing.T) {
+	filename := writeTestPlan(t)
+	api := NewAPI(APIOptions{InputPath: filename})
+
+	var activity models.Activity
+	status := doRequest(t, api, "PUT", "/api/persons/Bob/activities/2/selection", `{"selection":"selected"}`, &activity)
+	if status != http.StatusOK || activity.SelectionFor("Bob") != models.Selected {
+		t.Fatalf("Expected selected activity but got %d %v", status, activity)
+	}
+
+	plan, err := ReadPlan(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Centers[0].Events[1].SelectionFor("Bob") != models.Selected {
+		t.Errorf("Expected selection to be written to the plan")
+	}
+
+	status = doRequest(t, api, "PUT", "/api/persons/Ann/activities/2/selection", `{"selection":"selected"}`, nil)
+	if status != http.StatusNotFound {
+		t.Errorf("Expected 404 but got %d", status)
+	}
+
+	status = doRequest(t, api, "PUT", "/api/persons/Bob/activities/2/selection", `{"selection":"maybe"}`, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected 400 but got %d", status)
+	}
+}
+
+func TestAPIConcurrentSelection(t *testing.T) {
+	filename := writeTestPlan(t)
+	api := NewAPI(APIOptions{InputPath: filename})
+
+	var wg sync.WaitGroup
+	for id := 1; id <= 3; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			target := fmt.Sprintf("/api/persons/Bob/activities/%d/selection", id)
+			doRequest(t, api, "PUT", target, `{"selection":"shortlisted"}`, nil)
+		}(id)
+	}
+	wg.Wait()
+
+	plan, err := ReadPlan(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range plan.Centers[0].Events {
+		if e.SelectionFor("Bob") != models.Shortlisted {
+			t.Errorf("Expected activity %d to be shortlisted but got %q", e.Id, e.SelectionFor("Bob"))
+		}
+	}
+}
+
+func TestAPIConflicts(t *testing.T) {
+	filename := writeTestPlan(t)
+	api := NewAPI(APIOptions{InputPath: filename})
+
+	var conflicts []*models.Conflict
+	doRequest(t, api, "GET", "/api/conflicts", "", &conflicts)
+	if len(conflicts) != 1 || conflicts[0].First.Id != 1 || conflicts[0].Second.Id != 2 {
+		t.Errorf("Expected a conflict between 1 and 2 but got %v", conflicts)
+	}
+
+	doRequest(t, api, "GET", "/api/conflicts?selected=true", "", &conflicts)
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts between selected activities but got %v", conflicts)
+	}
+}
+
+func TestAPIOpenAPI(t *testing.T) {
+	api := NewAPI(APIOptions{})
+
+	req := httptest.NewRequest("GET", "/api/openapi.yaml", nil)
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	if !strings.HasPrefix(rec.Body.String(), "openapi:") {
+		t.Errorf("Expected the OpenAPI description")
+	}
+}
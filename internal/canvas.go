@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+const (
+	DefaultCanvasWidth  = 1400
+	DefaultCanvasHeight = 1000
+	DefaultFontSize     = 10
+	DefaultFontFamily   = "Helvetica, Arial, sans-serif"
+)
+
+type CanvasOptions struct {
+	Width      int
+	Height     int
+	FontFamily string
+	FontSize   float64
+	// FontPath is a TrueType or OpenType font used when rasterising. The Go
+	// regular font is used when it is empty.
+	FontPath string
+}
+
+func (o CanvasOptions) withDefaults() CanvasOptions {
+	if o.Width <= 0 {
+		o.Width = DefaultCanvasWidth
+	}
+	if o.Height <= 0 {
+		o.Height = DefaultCanvasHeight
+	}
+	if o.FontFamily == "" {
+		o.FontFamily = DefaultFontFamily
+	}
+	if o.FontSize <= 0 {
+		o.FontSize = DefaultFontSize
+	}
+	return o
+}
+
+type rect struct {
+	X, Y, W, H float64
+}
+
+type canvasText struct {
+	X, Y float64
+	Text string
+	Bold bool
+	Size float64
+}
+
+type canvasEvent struct {
+	rect
+	Fill  string
+	Href  string
+	Lines []string
+}
+
+// canvas is the geometry of a view, shared by the SVG and PNG renderers. It
+// mirrors the CSS grid used by the HTML template: a time column followed by
+// one column per weekday, each weekday split into as many sub-columns as its
+// widest overlap.
+type canvas struct {
+	Width  float64
+	Height float64
+
+	Labels []canvasText
+	Lines  []rect
+	Days   []rect
+	Events []canvasEvent
+}
+
+const (
+	timeColumnWidth = 50
+	titleHeight     = 30
+	headerHeight    = 20
+	rowGap          = 1
+)
+
+func newCanvas(view *models.View, options CanvasOptions) *canvas {
+	options = options.withDefaults()
+
+	c := &canvas{
+		Width:  float64(options.Width),
+		Height: float64(options.Height),
+	}
+
+	centers := len(view.Centers)
+	if centers == 0 {
+		return c
+	}
+
+	rowIndex := map[string]int{}
+	for i, t := range view.Times {
+		rowIndex[t.Code] = i
+	}
+
+	centerHeight := c.Height / float64(centers)
+	gridHeight := centerHeight - titleHeight - headerHeight
+	rowHeight := gridHeight / float64(max(len(view.Times), 1))
+	dayWidth := (c.Width - timeColumnWidth - 1) / float64(max(len(view.Days), 1))
+
+	for ci, cv := range view.Centers {
+		top := float64(ci) * centerHeight
+		gridTop := top + titleHeight + headerHeight
+
+		c.Labels = append(c.Labels, canvasText{
+			X:    2,
+			Y:    top + titleHeight*0.7,
+			Text: cv.CenterName,
+			Bold: true,
+			Size: options.FontSize * 1.6,
+		})
+
+		for i, t := range view.Times {
+			if i%4 != 0 {
+				continue
+			}
+			y := gridTop + float64(i)*rowHeight
+			c.Labels = append(c.Labels, canvasText{
+				X:    2,
+				Y:    y + options.FontSize,
+				Text: t.Name,
+				Size: options.FontSize,
+			})
+			c.Lines = append(c.Lines, rect{timeColumnWidth, y, c.Width - timeColumnWidth, 0})
+		}
+
+		for di, day := range view.Days {
+			x := timeColumnWidth + float64(di)*dayWidth
+			c.Labels = append(c.Labels, canvasText{
+				X:    x + 2,
+				Y:    top + titleHeight + headerHeight*0.7,
+				Text: day.ShortName,
+				Bold: true,
+				Size: options.FontSize,
+			})
+			c.Days = append(c.Days, rect{x, gridTop, dayWidth, gridHeight})
+
+			if di >= len(cv.Weekdays) {
+				continue
+			}
+
+			wdv := cv.Weekdays[di]
+			columnWidth := dayWidth / float64(max(wdv.Span, 1))
+			for _, e := range wdv.Events {
+				row, ok := rowIndex[e.StartTime]
+				if !ok {
+					continue
+				}
+
+				rows := max(e.Duration/15, 1)
+				fill := e.BgColor
+				if fill == "" {
+					fill = "white"
+				}
+				lines := []string{e.Activity.Name, e.Activity.TimeRange}
+				if len(e.Persons) > 0 {
+					lines = append(lines, strings.Join(e.Persons, ", "))
+				}
+				c.Events = append(c.Events, canvasEvent{
+					rect: rect{
+						X: x + float64(e.Offset)*columnWidth + 1,
+						Y: gridTop + float64(row)*rowHeight + rowGap,
+						W: float64(e.Span)*columnWidth - 2,
+						H: float64(rows)*rowHeight - 2*rowGap,
+					},
+					Fill:  fill,
+					Href:  e.Activity.DetailUrl,
+					Lines: lines,
+				})
+			}
+		}
+	}
+
+	return c
+}
+
+var rgbPattern = regexp.MustCompile(`^rgba?\(\s*(\d+)\s*,\s*(\d+)\s*,\s*(\d+)\s*(?:,\s*[\d.]+\s*)?\)$`)
+
+// parseColour understands the colours produced by models.NewCenterView: CSS
+// rgb() values, #rrggbb values and a handful of names.
+func parseColour(s string) (color.RGBA, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	switch s {
+	case "", "white":
+		return color.RGBA{255, 255, 255, 255}, nil
+	case "black":
+		return color.RGBA{0, 0, 0, 255}, nil
+	}
+
+	if matches := rgbPattern.FindStringSubmatch(s); matches != nil {
+		values := [3]uint8{}
+		for i := range values {
+			v, err := strconv.ParseUint(matches[i+1], 10, 8)
+			if err != nil {
+				return color.RGBA{}, fmt.Errorf("invalid colour %v", s)
+			}
+			values[i] = uint8(v)
+		}
+		return color.RGBA{values[0], values[1], values[2], 255}, nil
+	}
+
+	if strings.HasPrefix(s, "#") && len(s) == 7 {
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid colour %v", s)
+		}
+		return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+	}
+
+	return color.RGBA{}, fmt.Errorf("unsupported colour %v", s)
+}
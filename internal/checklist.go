@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+const DefaultChecklistTemplate = "./templates/checklist.html.gotmpl"
+
+// RenderChecklist writes the checklist in the given style: text, markdown or
+// html, the same styles as the agenda.
+func RenderChecklist(w io.Writer, checklist *models.Checklist, style string) error {
+	switch style {
+	case "", AgendaText:
+		return renderChecklistText(w, checklist)
+	case AgendaMarkdown, "md":
+		return renderChecklistMarkdown(w, checklist)
+	case AgendaHTML:
+		return renderChecklistHTML(w, checklist, DefaultChecklistTemplate)
+	}
+
+	return fmt.Errorf("unknown checklist style %v", style)
+}
+
+func renderChecklistText(w io.Writer, checklist *models.Checklist) error {
+	b := bufio.NewWriter(w)
+	title := fmt.Sprintf("Registration checklist for %v", checklist.Person)
+	fmt.Fprintln(b, title)
+	fmt.Fprintln(b, strings.Repeat("=", len(title)))
+
+	for _, slot := range checklist.Slots {
+		a := slot.Entry.Activity
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "%2d. [ ] %-8s %s\n", slot.Priority, a.Number, a.Name)
+		fmt.Fprintf(b, "           %s %s, %s\n", a.DayOfWeek, a.TimeRange, slot.Entry.CenterName)
+		if a.DetailUrl != "" {
+			fmt.Fprintf(b, "           %s\n", a.DetailUrl)
+		}
+		for i, alt := range slot.Alternates {
+			fmt.Fprintf(b, "    %c. [ ] %-8s %s, %s %s, %s\n", 'a'+i, alt.Activity.Number, alt.Activity.Name, alt.Activity.DayOfWeek, alt.Activity.TimeRange, alt.CenterName)
+		}
+	}
+
+	return b.Flush()
+}
+
+func renderChecklistMarkdown(w io.Writer, checklist *models.Checklist) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "## Registration checklist for %s\n", checklist.Person)
+	for _, slot := range checklist.Slots {
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "%d. %s\n", slot.Priority, markdownChecklistEntry(slot.Entry))
+		for _, alt := range slot.Alternates {
+			fmt.Fprintf(b, "   - %s\n", markdownChecklistEntry(alt))
+		}
+	}
+
+	return b.Flush()
+}
+
+func markdownChecklistEntry(e *models.ChecklistEntry) string {
+	a := e.Activity
+	name := a.Name
+	if a.DetailUrl != "" {
+		name = fmt.Sprintf("[%s](%s)", a.Name, a.DetailUrl)
+	}
+	return fmt.Sprintf("[ ] **#%s** %s, %s %s, %s", a.Number, name, a.DayOfWeek, a.TimeRange, e.CenterName)
+}
+
+func renderChecklistHTML(w io.Writer, checklist *models.Checklist, filename string) error {
+	name := path.Base(filename)
+	tmpl, err := template.New(name).Funcs(templateFuncs()).ParseFiles(filename)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, checklist)
+}
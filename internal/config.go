@@ -0,0 +1,442 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/snocorp/gojoin/models"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultTenant = "ottawa"
+
+// Profile holds values for the flags shared by the commands that search for
+// activities. Empty values are unset.
+type Profile struct {
+	Season   string `yaml:"season,omitempty" toml:"season,omitempty"`
+	Center   string `yaml:"center,omitempty" toml:"center,omitempty"`
+	Category string `yaml:"category,omitempty" toml:"category,omitempty"`
+	Search   string `yaml:"search,omitempty" toml:"search,omitempty"`
+	Output   string `yaml:"output,omitempty" toml:"output,omitempty"`
+	Person   string `yaml:"person,omitempty" toml:"person,omitempty"`
+}
+
+// Values returns the profile keyed by flag name.
+func (p Profile) Values() map[string]string {
+	return map[string]string{
+		"season":   p.Season,
+		"center":   p.Center,
+		"category": p.Category,
+		"search":   p.Search,
+		"output":   p.Output,
+		"person":   p.Person,
+	}
+}
+
+// merge returns the profile with the values set in other replacing its own.
+func (p Profile) merge(other Profile) Profile {
+	if other.Season != "" {
+		p.Season = other.Season
+	}
+	if other.Center != "" {
+		p.Center = other.Center
+	}
+	if other.Category != "" {
+		p.Category = other.Category
+	}
+	if other.Search != "" {
+		p.Search = other.Search
+	}
+	if other.Output != "" {
+		p.Output = other.Output
+	}
+	if other.Person != "" {
+		p.Person = other.Person
+	}
+	return p
+}
+
+// NotifyConfig configures where gojoin watch sends notifications, in addition
+// to stdout.
+type NotifyConfig struct {
+	// Command is run by the shell for each notification.
+	Command string      `yaml:"command,omitempty" toml:"command,omitempty"`
+	Webhook string      `yaml:"webhook,omitempty" toml:"webhook,omitempty"`
+	SMTP    *SMTPConfig `yaml:"smtp,omitempty" toml:"smtp,omitempty"`
+}
+
+// SMTPConfig is a mail server to send notifications through. The password may
+// instead be given by GOJOIN_SMTP_PASSWORD.
+type SMTPConfig struct {
+	Host     string   `yaml:"host" toml:"host"`
+	Port     int      `yaml:"port,omitempty" toml:"port,omitempty"`
+	Username string   `yaml:"username,omitempty" toml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty" toml:"password,omitempty"`
+	From     string   `yaml:"from" toml:"from"`
+	To       []string `yaml:"to" toml:"to"`
+}
+
+// DiscountConfig is a discount on the fees of gojoin cost, such as a second
+// child discount or a subsidy.
+type DiscountConfig struct {
+	Name    string  `yaml:"name" toml:"name"`
+	Percent float64 `yaml:"percent" toml:"percent"`
+	// Persons are the persons it applies to, everybody when empty.
+	Persons []string `yaml:"persons,omitempty" toml:"persons,omitempty"`
+	// FromPerson applies it from the nth person onwards, in the order of
+	// CostConfig.Persons.
+	FromPerson int `yaml:"from_person,omitempty" toml:"from_person,omitempty"`
+}
+
+// CostConfig holds the discounts and budgets, in dollars, of gojoin cost.
+type CostConfig struct {
+	// Persons is the order of the persons for FromPerson, eldest first,
+	// those not listed following in alphabetical order.
+	Persons   []string           `yaml:"persons,omitempty" toml:"persons,omitempty"`
+	Budget    float64            `yaml:"budget,omitempty" toml:"budget,omitempty"`
+	Budgets   map[string]float64 `yaml:"budgets,omitempty" toml:"budgets,omitempty"`
+	Discounts []DiscountConfig   `yaml:"discounts,omitempty" toml:"discounts,omitempty"`
+}
+
+// Options returns the discounts and budgets as cost report options.
+func (c CostConfig) Options() models.CostOptions {
+	options := models.CostOptions{
+		Persons:   c.Persons,
+		Budget:    models.Dollars(c.Budget),
+		Budgets:   map[string]models.Money{},
+		Discounts: []models.Discount{},
+	}
+	for person, budget := range c.Budgets {
+		options.Budgets[person] = models.Dollars(budget)
+	}
+	for _, d := range c.Discounts {
+		options.Discounts = append(options.Discounts, models.Discount{
+			Name:       d.Name,
+			Percent:    d.Percent,
+			Persons:    d.Persons,
+			FromPerson: d.FromPerson,
+		})
+	}
+	return options
+}
+
+// TravelConfig sets how travel times between centers are estimated.
+type TravelConfig struct {
+	// Speed is the straight-line speed in km/h, DefaultTravelSpeed when zero.
+	Speed float64 `yaml:"speed,omitempty" toml:"speed,omitempty"`
+	// Matrix holds travel times in minutes between centers, by ID or name,
+	// which replace the straight-line estimates.
+	Matrix map[string]map[string]float64 `yaml:"matrix,omitempty" toml:"matrix,omitempty"`
+	// Locations is a file of center locations overriding those looked up,
+	// DefaultLocationsPath when empty.
+	Locations string `yaml:"locations,omitempty" toml:"locations,omitempty"`
+}
+
+// OrderConfig is the user order of the centers, by ID or name, and of the
+// persons in the views. Those it doesn't list follow in plan order.
+type OrderConfig struct {
+	Centers []string `yaml:"centers,omitempty" toml:"centers,omitempty"`
+	Persons []string `yaml:"persons,omitempty" toml:"persons,omitempty"`
+}
+
+// HomeConfig is where the family lives, from which distances to centers are
+// measured.
+type HomeConfig struct {
+	Latitude  float64 `yaml:"latitude" toml:"latitude"`
+	Longitude float64 `yaml:"longitude" toml:"longitude"`
+}
+
+// Location returns home as a location.
+func (h HomeConfig) Location() models.CenterLocation {
+	return models.CenterLocation{CenterName: "home", Latitude: h.Latitude, Longitude: h.Longitude}
+}
+
+// WindowConfig is a weekly window of availability. Days are day names,
+// weekdays or weekends, every day when empty, and From and To are times like
+// 15:15, the start and end of the day when empty.
+type WindowConfig struct {
+	Days []string `yaml:"days,omitempty" toml:"days,omitempty"`
+	From string   `yaml:"from,omitempty" toml:"from,omitempty"`
+	To   string   `yaml:"to,omitempty" toml:"to,omitempty"`
+}
+
+type Config struct {
+	// Tenant is the ActiveNet organisation, the path of its site.
+	Tenant   string             `yaml:"tenant,omitempty" toml:"tenant,omitempty"`
+	Defaults Profile            `yaml:"defaults,omitempty" toml:"defaults,omitempty"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+	Notify   NotifyConfig       `yaml:"notify,omitempty" toml:"notify,omitempty"`
+	Cost     CostConfig         `yaml:"cost,omitempty" toml:"cost,omitempty"`
+	Travel   TravelConfig       `yaml:"travel,omitempty" toml:"travel,omitempty"`
+	Order    OrderConfig        `yaml:"order,omitempty" toml:"order,omitempty"`
+	Home     *HomeConfig        `yaml:"home,omitempty" toml:"home,omitempty"`
+	// Availability holds the weekly windows of each person, and of the
+	// drivers under models.DriversKey.
+	Availability map[string][]WindowConfig `yaml:"availability,omitempty" toml:"availability,omitempty"`
+	// Names are the rules parsing activity names, regular expressions with
+	// program, level, indigenous, english and ages groups, keyed by tenant.
+	Names map[string][]string `yaml:"names,omitempty" toml:"names,omitempty"`
+
+	// Sources are the files the configuration was read from, in the order
+	// they were applied.
+	Sources []string `yaml:"-" toml:"-"`
+
+	// env holds the values set in the environment, which take precedence
+	// over every profile
+	env Profile
+}
+
+// Redacted returns a copy of the configuration without its secrets, the SMTP
+// password and the webhook URL, for printing.
+func (c *Config) Redacted() Config {
+	redacted := *c
+	if c.Notify.Webhook != "" {
+		redacted.Notify.Webhook = redactedValue
+	}
+	if c.Notify.SMTP != nil && c.Notify.SMTP.Password != "" {
+		smtp := *c.Notify.SMTP
+		smtp.Password = redactedValue
+		redacted.Notify.SMTP = &smtp
+	}
+	return redacted
+}
+
+const redactedValue = "REDACTED"
+
+// BaseUrl returns the ActiveNet site for the tenant.
+func (c *Config) BaseUrl() string {
+	tenant := c.Tenant
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	return "https://anc.ca.apm.activecommunities.com/" + tenant
+}
+
+// NameRules returns the name rules of the tenant, tried before the default
+// rules.
+func (c *Config) NameRules() (models.NameRules, error) {
+	tenant := c.Tenant
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+
+	rules := models.NameRules{}
+	for _, pattern := range c.Names[tenant] {
+		rule, err := models.ParseNameRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// PersonAvailability returns the availability of the person, false when it
+// isn't configured.
+func (c *Config) PersonAvailability(person string) (models.Availability, bool, error) {
+	windows, ok := c.Availability[person]
+	if !ok {
+		return nil, false, nil
+	}
+
+	availability := models.Availability{}
+	for _, w := range windows {
+		window, err := models.ParseWindow(w.Days, w.From, w.To)
+		if err != nil {
+			return nil, false, fmt.Errorf("availability of %v: %w", person, err)
+		}
+		availability = append(availability, window)
+	}
+	return availability, true, nil
+}
+
+// CenterDistances returns the distance in km from home to each center whose
+// location is known, nil when there is no home.
+func (c *Config) CenterDistances() (map[string]float64, error) {
+	if c.Home == nil {
+		return nil, nil
+	}
+
+	locations, err := LoadCenterLocations(c.BaseUrl(), c.Travel)
+	if err != nil {
+		return nil, err
+	}
+	return models.CenterDistances(locations, c.Home.Location()), nil
+}
+
+// Profile returns the defaults overridden by the named profile, if any, and
+// then by the environment.
+func (c *Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		return c.Defaults.merge(c.env), nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := []string{}
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("unknown profile %v, available profiles: %v", name, names)
+	}
+
+	return c.Defaults.merge(profile).merge(c.env), nil
+}
+
+func (c *Config) merge(other *Config) {
+	if other.Tenant != "" {
+		c.Tenant = other.Tenant
+	}
+	c.Defaults = c.Defaults.merge(other.Defaults)
+	if other.Notify.Command != "" {
+		c.Notify.Command = other.Notify.Command
+	}
+	if other.Notify.Webhook != "" {
+		c.Notify.Webhook = other.Notify.Webhook
+	}
+	if other.Notify.SMTP != nil {
+		c.Notify.SMTP = other.Notify.SMTP
+	}
+	if other.Cost.Persons != nil {
+		c.Cost.Persons = other.Cost.Persons
+	}
+	if other.Cost.Budget != 0 {
+		c.Cost.Budget = other.Cost.Budget
+	}
+	for person, budget := range other.Cost.Budgets {
+		if c.Cost.Budgets == nil {
+			c.Cost.Budgets = map[string]float64{}
+		}
+		c.Cost.Budgets[person] = budget
+	}
+	if other.Cost.Discounts != nil {
+		c.Cost.Discounts = other.Cost.Discounts
+	}
+	if other.Travel.Speed != 0 {
+		c.Travel.Speed = other.Travel.Speed
+	}
+	if other.Travel.Locations != "" {
+		c.Travel.Locations = other.Travel.Locations
+	}
+	for from, times := range other.Travel.Matrix {
+		if c.Travel.Matrix == nil {
+			c.Travel.Matrix = map[string]map[string]float64{}
+		}
+		c.Travel.Matrix[from] = times
+	}
+	if other.Order.Centers != nil {
+		c.Order.Centers = other.Order.Centers
+	}
+	if other.Order.Persons != nil {
+		c.Order.Persons = other.Order.Persons
+	}
+	if other.Home != nil {
+		c.Home = other.Home
+	}
+	for person, windows := range other.Availability {
+		if c.Availability == nil {
+			c.Availability = map[string][]WindowConfig{}
+		}
+		c.Availability[person] = windows
+	}
+	for tenant, rules := range other.Names {
+		if c.Names == nil {
+			c.Names = map[string][]string{}
+		}
+		c.Names[tenant] = rules
+	}
+	for name, profile := range other.Profiles {
+		if c.Profiles == nil {
+			c.Profiles = map[string]Profile{}
+		}
+		c.Profiles[name] = c.Profiles[name].merge(profile)
+	}
+}
+
+// ConfigFiles returns the configuration files that are read, from lowest to
+// highest precedence: the user's config directory and then the working
+// directory.
+func ConfigFiles() ([]string, error) {
+	files := []string{}
+
+	configDir, err := os.UserConfigDir()
+	if err == nil {
+		dir := filepath.Join(configDir, "gojoin")
+		files = append(files, filepath.Join(dir, "config.yaml"), filepath.Join(dir, "config.toml"))
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, filepath.Join(wd, ".gojoin.yaml"), filepath.Join(wd, ".gojoin.toml"))
+
+	return files, nil
+}
+
+// LoadConfig reads and merges the configuration files that exist and then
+// applies the environment: GOJOIN_TENANT and GOJOIN_<FLAG> for each profile
+// value.
+func LoadConfig() (*Config, error) {
+	files, err := ConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{Profiles: map[string]Profile{}, Sources: []string{}}
+	for _, f := range files {
+		fileConfig, err := readConfigFile(f)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", f, err)
+		}
+
+		config.merge(fileConfig)
+		config.Sources = append(config.Sources, f)
+	}
+
+	if tenant := os.Getenv("GOJOIN_TENANT"); tenant != "" {
+		config.Tenant = tenant
+	}
+	if password := os.Getenv("GOJOIN_SMTP_PASSWORD"); password != "" && config.Notify.SMTP != nil {
+		config.Notify.SMTP.Password = password
+	}
+	config.env = Profile{
+		Season:   os.Getenv("GOJOIN_SEASON"),
+		Center:   os.Getenv("GOJOIN_CENTER"),
+		Category: os.Getenv("GOJOIN_CATEGORY"),
+		Search:   os.Getenv("GOJOIN_SEARCH"),
+		Output:   os.Getenv("GOJOIN_OUTPUT"),
+		Person:   os.Getenv("GOJOIN_PERSON"),
+	}
+
+	return config, nil
+}
+
+func readConfigFile(filename string) (*Config, error) {
+	configBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	switch filepath.Ext(filename) {
+	case ".toml":
+		err = toml.Unmarshal(configBytes, &config)
+	default:
+		err = yaml.Unmarshal(configBytes, &config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	tomlPath := filepath.Join(dir, ".gojoin.toml")
+
+	err := os.WriteFile(yamlPath, []byte("tenant: ottawa\ndefaults:\n  season: \"46\"\n  center: \"165\"\nprofiles:\n  swim:\n    category: \"25\"\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(tomlPath, []byte("[profiles.swim]\ncenter = \"384\"\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{}
+	for _, f := range []string{yamlPath, tomlPath} {
+		fileConfig, err := readConfigFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.merge(fileConfig)
+	}
+	config.env = Profile{Season: "47"}
+
+	p, err := config.Profile("swim")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Profile{Season: "47", Center: "384", Category: "25"}
+	if p != expected {
+		t.Errorf("Expected %v but got %v", expected, p)
+	}
+
+	if config.BaseUrl() != "https://anc.ca.apm.activecommunities.com/ottawa" {
+		t.Errorf("Unexpected base URL %v", config.BaseUrl())
+	}
+
+	_, err = config.Profile("missing")
+	if err == nil {
+		t.Errorf("Expected an error for an unknown profile")
+	}
+}
+
+func TestConfigRedacted(t *testing.T) {
+	config := &Config{Notify: NotifyConfig{
+		Webhook: "https://hooks.example.com/token",
+		SMTP:    &SMTPConfig{Host: "smtp.example.com", Password: "hunter2"},
+	}}
+
+	redacted := config.Redacted()
+	if redacted.Notify.Webhook != redactedValue || redacted.Notify.SMTP.Password != redactedValue {
+		t.Errorf("Expected the webhook and the password to be redacted but got %+v and %+v", redacted.Notify, redacted.Notify.SMTP)
+	}
+	if redacted.Notify.SMTP.Host != "smtp.example.com" {
+		t.Errorf("Expected the SMTP host to be kept but got %v", redacted.Notify.SMTP.Host)
+	}
+	if config.Notify.SMTP.Password != "hunter2" {
+		t.Errorf("Expected the config to keep its password but got %v", config.Notify.SMTP.Password)
+	}
+}
@@ -0,0 +1,32 @@
+package internal
+
+import "fmt"
+
+// RequestError is a failed request to ActiveNet: it couldn't be reached or
+// the response was unexpected.
+type RequestError struct {
+	Url string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("request to %v failed: %v", e.Url, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// PlanError is a plan file that couldn't be read, parsed or written.
+type PlanError struct {
+	Path string
+	Err  error
+}
+
+func (e *PlanError) Error() string {
+	return fmt.Sprintf("plan %v: %v", e.Path, e.Err)
+}
+
+func (e *PlanError) Unwrap() error {
+	return e.Err
+}
@@ -2,88 +2,161 @@ package internal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/snocorp/gojoin/models"
 )
 
 type GetFiltersOptions struct {
+	// BaseUrl is the ActiveNet site to query, DefaultBaseUrl when empty.
+	BaseUrl string
 	NoCache bool
-	Verbose bool
 }
 
 func GetFilters(options GetFiltersOptions) (body models.FiltersBody, err error) {
 	loadedCachedFilter := false
 	useCache := !options.NoCache
 
-	var filterBytes []byte
 	var filters models.FiltersResponse
 	if useCache {
-		wd, err := os.Getwd()
+		cached, err := ReadCachedFilters(options.BaseUrl)
 		if err != nil {
-			return body, err
-		}
-
-		cacheDir := path.Join(wd, ".gojoin", "cache")
-
-		filterBytes, err = os.ReadFile(path.Join(cacheDir, "filters.json"))
-		if err != nil {
-			if options.Verbose {
-				fmt.Println(err)
-			}
+			slog.Debug("unable to read cached filters", "error", err)
 		} else {
-			err = json.Unmarshal(filterBytes, &filters)
-			if err != nil {
-				if options.Verbose {
-					fmt.Println(err)
-				}
-			} else {
-				loadedCachedFilter = true
-			}
+			filters.Body = cached
+			loadedCachedFilter = true
 		}
 	}
 
 	if !loadedCachedFilter {
+		baseUrl := options.BaseUrl
+		if baseUrl == "" {
+			baseUrl = DefaultBaseUrl
+		}
+
 		now := time.Now().UnixMilli()
-		resp, err := http.Get(fmt.Sprintf("https://anc.ca.apm.activecommunities.com/ottawa/rest/activities/filters?locale=en-US&ui_random=%v", now))
+		url := fmt.Sprintf("%v/rest/activities/filters?locale=en-US&ui_random=%v", baseUrl, now)
+		slog.Debug("requesting filters", "url", url)
+		resp, err := http.Get(url)
 		if err != nil {
-			return models.FiltersBody{}, err
+			return models.FiltersBody{}, &RequestError{url, err}
 		}
 		defer resp.Body.Close()
-		filterBytes, err = io.ReadAll(resp.Body)
+		if resp.StatusCode != 200 {
+			return models.FiltersBody{}, &RequestError{url, fmt.Errorf("unexpected status %v", resp.StatusCode)}
+		}
+
+		filterBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return models.FiltersBody{}, err
+			return models.FiltersBody{}, &RequestError{url, err}
 		}
 
 		err = json.Unmarshal(filterBytes, &filters)
 		if err != nil {
-			return models.FiltersBody{}, err
+			return models.FiltersBody{}, &RequestError{url, err}
 		}
 
-		wd, err := os.Getwd()
+		err = updateCachedFilters(baseUrl, func(cached *models.FiltersResponse) {
+			// keep the center locations looked up since the filters were
+			// cached, which ActiveNet doesn't return
+			filters.Body.Locations = cached.Body.Locations
+			filters.Body.LocationFailures = cached.Body.LocationFailures
+			*cached = filters
+		})
 		if err != nil {
-			return body, err
+			slog.Warn("unable to cache filters", "error", err)
 		}
+	}
+
+	return filters.Body, nil
+}
+
+// ReadCachedFilters reads the filters of the ActiveNet site cached by
+// GetFilters without making any request.
+func ReadCachedFilters(baseUrl string) (models.FiltersBody, error) {
+	cachePath, err := filtersCachePath(baseUrl)
+	if err != nil {
+		return models.FiltersBody{}, err
+	}
+
+	filterBytes, err := os.ReadFile(cachePath)
+	if err != nil {
+		return models.FiltersBody{}, err
+	}
 
-		cacheDir := path.Join(wd, ".gojoin", "cache")
+	var filters models.FiltersResponse
+	err = json.Unmarshal(filterBytes, &filters)
+	if err != nil {
+		return models.FiltersBody{}, err
+	}
 
-		err = os.MkdirAll(cacheDir, 0775)
+	return filters.Body, nil
+}
+
+// updateCachedFilters applies the update to the cached filters, empty when
+// there are none, and replaces the cache while holding its lock, so that a
+// refresh and location lookups by other processes don't lose each other's
+// changes.
+func updateCachedFilters(baseUrl string, update func(filters *models.FiltersResponse)) error {
+	cachePath, err := filtersCachePath(baseUrl)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(path.Dir(cachePath), 0775)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(cachePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var filters models.FiltersResponse
+	data, err := os.ReadFile(cachePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &filters)
 		if err != nil {
-			if options.Verbose {
-				fmt.Println(err)
-			}
-		} else {
-			err = os.WriteFile(path.Join(cacheDir, "filters.json"), filterBytes, 0664)
-			if err != nil && options.Verbose {
-				fmt.Println(err)
-			}
+			slog.Debug("replacing unreadable cached filters", "path", cachePath, "error", err)
+			filters = models.FiltersResponse{}
 		}
 	}
 
-	return filters.Body, nil
+	update(&filters)
+
+	data, err = json.Marshal(filters)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(cachePath, data, 0664)
+}
+
+// filtersCachePath returns the cache of the filters of the ActiveNet site,
+// kept apart for each tenant.
+func filtersCachePath(baseUrl string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	if baseUrl == "" {
+		baseUrl = DefaultBaseUrl
+	}
+	tenant := path.Base(strings.TrimSuffix(baseUrl, "/"))
+
+	return path.Join(wd, ".gojoin", "cache", tenant, "filters.json"), nil
 }
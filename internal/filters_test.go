@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+func TestGetFiltersKeepsLocations(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	activeNet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ottawa/rest/activities/filters":
+			fmt.Fprint(w, `{"headers":{},"body":{"centers":[{"id":"165","desc":"Plant Recreation Centre"}]}}`)
+		case "/ottawa/rest/activities/list":
+			fmt.Fprint(w, `{"body":{"activity_items":[{"location":{"label":"Plant","latitude":45.4087,"longitude":-75.7162}}]}}`)
+		default:
+			t.Errorf("Unexpected request %v %v", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer activeNet.Close()
+	baseUrl := activeNet.URL + "/ottawa"
+
+	_, err = GetFilters(GetFiltersOptions{BaseUrl: baseUrl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = EnsureCenterLocation(baseUrl, "165", "Plant Recreation Centre")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := GetFilters(GetFiltersOptions{BaseUrl: baseUrl, NoCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters.Centers) != 1 {
+		t.Errorf("Expected the refreshed centers but got %v", filters.Centers)
+	}
+
+	cached, err := ReadCachedFilters(baseUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached.Locations) != 1 || cached.Locations[0].CenterId != "165" {
+		t.Errorf("Expected the location of center 165 to survive the refresh but got %v", cached.Locations)
+	}
+}
+
+func TestEnsureCenterLocations(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	var mu sync.Mutex
+	lookups := map[string]int{}
+	activeNet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ottawa/rest/activities/filters":
+			fmt.Fprint(w, `{"headers":{},"body":{"centers":[{"id":"165","desc":"Plant"},{"id":"166","desc":"Jack Purcell"},{"id":"999","desc":"Closed"}]}}`)
+		case "/ottawa/rest/activities/list":
+			body, _ := io.ReadAll(r.Body)
+			var request models.ActivityRequest
+			json.Unmarshal(body, &request)
+			id := request.SearchPattern.CenterIds[0]
+			mu.Lock()
+			lookups[id]++
+			mu.Unlock()
+
+			if id == "999" {
+				fmt.Fprint(w, `{"body":{"activity_items":[]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"body":{"activity_items":[{"location":{"label":"Center","latitude":45.4,"longitude":-75.7}}]}}`)
+		default:
+			t.Errorf("Unexpected request %v %v", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer activeNet.Close()
+	baseUrl := activeNet.URL + "/ottawa"
+
+	filters, err := GetFilters(GetFiltersOptions{BaseUrl: baseUrl})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the lookups of separate processes don't lose each other's locations
+	var wg sync.WaitGroup
+	for _, c := range filters.Centers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			EnsureCenterLocation(baseUrl, c.Id, c.Description)
+		}()
+	}
+	wg.Wait()
+
+	err = EnsureCenterLocations(baseUrl, filters.Centers)
+	if err != nil {
+		t.Errorf("Expected the failed lookup not to be retried but got %v", err)
+	}
+
+	for _, id := range []string{"165", "166", "999"} {
+		if lookups[id] != 1 {
+			t.Errorf("Expected center %v to be looked up once but got %v", id, lookups[id])
+		}
+	}
+
+	cached, err := ReadCachedFilters(baseUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached.Locations) != 2 {
+		t.Errorf("Expected the locations of 2 centers but got %v", cached.Locations)
+	}
+	if _, ok := cached.LocationFailures["999"]; !ok || len(cached.LocationFailures) != 1 {
+		t.Errorf("Expected center 999 to have failed but got %v", cached.LocationFailures)
+	}
+
+	cached.LocationFailures["999"] = time.Now().Add(-LocationRetryInterval)
+	err = updateCachedFilters(baseUrl, func(filters *models.FiltersResponse) {
+		filters.Body = cached
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = EnsureCenterLocations(baseUrl, filters.Centers)
+	if err == nil || lookups["999"] != 2 {
+		t.Errorf("Expected the failed lookup to be retried after %v but got %v after %v lookups", LocationRetryInterval, err, lookups["999"])
+	}
+}
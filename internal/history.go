@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+// DefaultHistoryPath is where the openings of every loaded, searched or
+// watched activity are recorded, one JSON snapshot per line.
+const DefaultHistoryPath = ".gojoin/history/openings.jsonl"
+
+// Snapshots returns the openings of the activities at the given time, leaving
+// out the activities whose openings are unknown or can't be parsed.
+func Snapshots(activities []*models.Activity, request models.ActivityRequest, centerName string, now time.Time) []*models.OpeningsSnapshot {
+	var seasonId, centerId string
+	if p := request.SearchPattern; p != nil {
+		if len(p.SeasonIds) == 1 {
+			seasonId = p.SeasonIds[0]
+		}
+		if len(p.CenterIds) == 1 {
+			centerId = p.CenterIds[0]
+		}
+	}
+
+	snapshots := []*models.OpeningsSnapshot{}
+	for _, a := range activities {
+		if a.Openings == "" {
+			continue
+		}
+
+		count, err := a.OpeningsCount()
+		if err != nil {
+			slog.Debug("not recording openings", "id", a.Id, "error", err)
+			continue
+		}
+
+		snapshots = append(snapshots, &models.OpeningsSnapshot{
+			Time:       now,
+			SeasonId:   seasonId,
+			CenterId:   centerId,
+			CenterName: centerName,
+			Id:         a.Id,
+			Name:       a.Name,
+			Number:     a.Number,
+			Openings:   a.Openings,
+			Count:      count,
+			Waitlist:   a.Waitlist,
+		})
+	}
+
+	return snapshots
+}
+
+// AppendSnapshots appends the snapshots to the history file, creating it if
+// needed. They are written at once so that concurrent commands don't
+// interleave their lines.
+func AppendSnapshots(filename string, snapshots []*models.OpeningsSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, s := range snapshots {
+		err := encoder.Encode(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(filename), 0775)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(buf.Bytes())
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// RecordOpenings appends the openings of the activities to the history at
+// DefaultHistoryPath. Failing to record them is only logged, the history is
+// not worth failing a command for.
+func RecordOpenings(activities []*models.Activity, request models.ActivityRequest, centerName string) {
+	snapshots := Snapshots(activities, request, centerName, time.Now())
+	err := AppendSnapshots(DefaultHistoryPath, snapshots)
+	if err != nil {
+		slog.Warn("couldn't record openings", "path", DefaultHistoryPath, "error", err)
+		return
+	}
+	slog.Debug("recorded openings", "path", DefaultHistoryPath, "count", len(snapshots))
+}
+
+// ReadSnapshots reads the history file. A line that can't be parsed, such as
+// one cut short by a crash, is an error naming the line.
+func ReadSnapshots(filename string) ([]*models.OpeningsSnapshot, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshots := []*models.OpeningsSnapshot{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var s models.OpeningsSnapshot
+		err = json.Unmarshal(scanner.Bytes(), &s)
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", filename, line, err)
+		}
+		snapshots = append(snapshots, &s)
+	}
+
+	return snapshots, scanner.Err()
+}
@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/snocorp/gojoin/models"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocationsPath is the file of center locations that override those
+// looked up on ActiveNet:
+//
+//   - center: Plant Recreation Centre
+//     latitude: 45.4087
+//     longitude: -75.7162
+const DefaultLocationsPath = ".gojoin/locations.yaml"
+
+// LocationOverride is the location of a center, by ID or name.
+type LocationOverride struct {
+	Center    string  `yaml:"center"`
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+}
+
+// FetchCenterLocation looks up the location of a center with a for_map search
+// of its activities, which gives their locations.
+func FetchCenterLocation(baseUrl string, centerId string) (*models.CenterLocation, error) {
+	if baseUrl == "" {
+		baseUrl = DefaultBaseUrl
+	}
+
+	requestBytes, err := json.Marshal(models.ActivityRequest{
+		SearchPattern: &models.ActivitySearchPattern{
+			CenterIds: []string{centerId},
+			ForMap:    true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url, body, err := postActivities(baseUrl, requestBytes, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.MapSearchResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		slog.Debug("unexpected response", "url", url, "body", string(body))
+		return nil, &RequestError{url, err}
+	}
+
+	if response.Body != nil {
+		for _, item := range response.Body.ActivityItems {
+			if l := item.Location; l != nil && (l.Latitude != 0 || l.Longitude != 0) {
+				return &models.CenterLocation{
+					CenterId:   centerId,
+					CenterName: l.Label,
+					Latitude:   l.Latitude,
+					Longitude:  l.Longitude,
+				}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no location found for center %v", centerId)
+}
+
+// EnsureCenterLocation looks up the location of the center unless it is
+// cached already and adds it to the cached filters.
+func EnsureCenterLocation(baseUrl string, centerId string, centerName string) error {
+	return EnsureCenterLocations(baseUrl, models.Criteria{{Id: centerId, Description: centerName}})
+}
+
+// LocationRetryInterval is how long EnsureCenterLocations waits before
+// looking up the location of a center again after failing to find it.
+const LocationRetryInterval = 24 * time.Hour
+
+// maxLocationLookups is the number of locations looked up at once.
+const maxLocationLookups = 4
+
+// EnsureCenterLocations looks up the locations of the centers that aren't
+// cached already and adds them to the cached filters. The locations found are
+// cached even when others fail, and the failures are cached so that they are
+// only retried after LocationRetryInterval.
+func EnsureCenterLocations(baseUrl string, centers models.Criteria) error {
+	filters, err := ReadCachedFilters(baseUrl)
+	if err != nil {
+		return err
+	}
+
+	skip := map[string]bool{}
+	for _, l := range filters.Locations {
+		skip[l.CenterId] = true
+	}
+	for id, failedAt := range filters.LocationFailures {
+		if time.Since(failedAt) < LocationRetryInterval {
+			slog.Debug("skipping center location that wasn't found recently", "center", id, "failed_at", failedAt)
+			skip[id] = true
+		}
+	}
+
+	missing := models.Criteria{}
+	for _, c := range centers {
+		if !skip[c.Id] {
+			missing = append(missing, c)
+			skip[c.Id] = true
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	locations := make([]*models.CenterLocation, len(missing))
+	errs := make([]error, len(missing))
+	limit := make(chan struct{}, maxLocationLookups)
+	var wg sync.WaitGroup
+	for i, c := range missing {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			location, err := FetchCenterLocation(baseUrl, c.Id)
+			if err != nil {
+				errs[i] = fmt.Errorf("%v: %w", c.Description, err)
+				return
+			}
+			location.CenterName = c.Description
+			slog.Debug("found center location", "center", c.Description, "latitude", location.Latitude, "longitude", location.Longitude)
+			locations[i] = location
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	err = updateCachedFilters(baseUrl, func(cached *models.FiltersResponse) {
+		body := &cached.Body
+		if body.LocationFailures == nil {
+			body.LocationFailures = map[string]time.Time{}
+		}
+		for i, c := range missing {
+			if locations[i] == nil {
+				body.LocationFailures[c.Id] = now
+				continue
+			}
+
+			// another process may have found it in the meantime
+			body.Locations = slices.DeleteFunc(body.Locations, func(l models.CenterLocation) bool {
+				return l.CenterId == c.Id
+			})
+			body.Locations = append(body.Locations, *locations[i])
+			delete(body.LocationFailures, c.Id)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// ReadLocationOverrides reads a file of center locations.
+func ReadLocationOverrides(filename string) ([]LocationOverride, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []LocationOverride
+	err = yaml.Unmarshal(data, &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return overrides, nil
+}
+
+// LoadCenterLocations returns the center locations of the ActiveNet site in
+// the filters cache replaced by those of the override file, by center ID.
+func LoadCenterLocations(baseUrl string, travel TravelConfig) (map[string]models.CenterLocation, error) {
+	filters, err := ReadCachedFilters(baseUrl)
+	if err != nil {
+		slog.Debug("no cached center locations", "error", err)
+	}
+
+	locations := map[string]models.CenterLocation{}
+	for _, l := range filters.Locations {
+		locations[l.CenterId] = l
+	}
+
+	path := travel.Locations
+	if path == "" {
+		path = DefaultLocationsPath
+	}
+	overrides, err := ReadLocationOverrides(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, o := range overrides {
+		id, err := resolveCenterId(filters, o.Center)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", path, err)
+		}
+		locations[id] = models.CenterLocation{CenterId: id, CenterName: o.Center, Latitude: o.Latitude, Longitude: o.Longitude}
+	}
+
+	return locations, nil
+}
+
+// LoadTravelTimes returns the travel times configured, using the center
+// locations of LoadCenterLocations.
+func LoadTravelTimes(baseUrl string, travel TravelConfig) (*models.TravelTimes, error) {
+	locations, err := LoadCenterLocations(baseUrl, travel)
+	if err != nil {
+		return nil, err
+	}
+
+	times := &models.TravelTimes{
+		Locations: locations,
+		Speed:     travel.Speed,
+		Matrix:    map[string]map[string]time.Duration{},
+	}
+
+	filters, _ := ReadCachedFilters(baseUrl)
+	for from, row := range travel.Matrix {
+		fromId, err := resolveCenterId(filters, from)
+		if err != nil {
+			return nil, fmt.Errorf("travel matrix: %w", err)
+		}
+		if times.Matrix[fromId] == nil {
+			times.Matrix[fromId] = map[string]time.Duration{}
+		}
+		for to, minutes := range row {
+			toId, err := resolveCenterId(filters, to)
+			if err != nil {
+				return nil, fmt.Errorf("travel matrix: %w", err)
+			}
+			times.Matrix[fromId][toId] = time.Duration(minutes * float64(time.Minute))
+		}
+	}
+
+	return times, nil
+}
+
+// resolveCenterId resolves a center given by ID or name when the filters are
+// cached, and otherwise takes it as an ID.
+func resolveCenterId(filters models.FiltersBody, center string) (string, error) {
+	if len(filters.Centers) == 0 {
+		return center, nil
+	}
+	c, err := models.Find("center", filters.Centers, center)
+	if err != nil {
+		return "", err
+	}
+	return c.Id, nil
+}
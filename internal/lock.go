@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockTimeout is how long LockPlan waits for another process to release the
+// lock.
+const LockTimeout = 30 * time.Second
+
+// LockPlan takes an advisory lock on the plan file, held until unlock is
+// called. Only gojoin processes respect the lock; it's a separate hidden file
+// next to the plan.
+func LockPlan(filename string) (unlock func() error, err error) {
+	return lockFile(filename)
+}
+
+// lockFile takes an advisory lock on the file like LockPlan.
+func lockFile(filename string) (unlock func() error, err error) {
+	lockName := filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".lock")
+	f, err := os.OpenFile(lockName, os.O_CREATE|os.O_RDWR, 0664)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("still locked by another gojoin process after %v", LockTimeout)
+		}
+		slog.Debug("waiting for the lock", "path", lockName)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return func() error {
+		err := unlockFile(f)
+		closeErr := f.Close()
+		if err != nil {
+			return err
+		}
+		return closeErr
+	}, nil
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package internal
+
+import (
+	"os"
+	"sync"
+)
+
+// Without flock, the lock only excludes other goroutines of this process.
+var fileLocks sync.Map
+
+func tryLock(f *os.File) (bool, error) {
+	_, loaded := fileLocks.LoadOrStore(f.Name(), true)
+	return !loaded, nil
+}
+
+func unlockFile(f *os.File) error {
+	fileLocks.Delete(f.Name())
+	return nil
+}
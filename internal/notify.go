@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notifier sends a notification that a watched activity has openings.
+type Notifier interface {
+	Notify(ctx context.Context, opening *Opening) error
+}
+
+// StdoutNotifier prints a line for each opening.
+type StdoutNotifier struct {
+	W io.Writer
+}
+
+func (n *StdoutNotifier) Notify(ctx context.Context, opening *Opening) error {
+	_, err := fmt.Fprintln(n.W, opening.Summary())
+	return err
+}
+
+// CommandNotifier runs a shell command for each opening. The opening is given
+// as JSON on stdin and in GOJOIN_* environment variables.
+type CommandNotifier struct {
+	Command string
+}
+
+func (n *CommandNotifier) Notify(ctx context.Context, opening *Opening) error {
+	body, err := json.Marshal(opening)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", n.Command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GOJOIN_ACTIVITY_ID="+strconv.Itoa(opening.Activity.Id),
+		"GOJOIN_ACTIVITY_NAME="+opening.Activity.Name,
+		"GOJOIN_ACTIVITY_NUMBER="+opening.Activity.Number,
+		"GOJOIN_ACTIVITY_URL="+opening.Activity.DetailUrl,
+		"GOJOIN_OPENINGS="+opening.Current,
+		"GOJOIN_PREVIOUS_OPENINGS="+opening.Previous,
+	)
+
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("notification command: %w", err)
+	}
+	return nil
+}
+
+// WebhookNotifier posts each opening as JSON to a URL.
+type WebhookNotifier struct {
+	Url    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, opening *Opening) error {
+	body, err := json.Marshal(opening)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status %v", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SMTPNotifier mails each opening.
+type SMTPNotifier struct {
+	Config SMTPConfig
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, opening *Opening) error {
+	port := n.Config.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := fmt.Sprintf("%v:%v", n.Config.Host, port)
+
+	var auth smtp.Auth
+	if n.Config.Username != "" {
+		auth = smtp.PlainAuth("", n.Config.Username, n.Config.Password, n.Config.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %v\r\n", n.Config.From)
+	fmt.Fprintf(&msg, "To: %v\r\n", strings.Join(n.Config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: Opening in %v\r\n", opening.Activity.Name)
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%v\r\n\r\nBarcode: %v\r\n%v\r\n", opening.Summary(), opening.Activity.Number, opening.Activity.DetailUrl)
+
+	err := smtp.SendMail(addr, auth, n.Config.From, n.Config.To, msg.Bytes())
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return nil
+}
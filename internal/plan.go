@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+// MaxBackups is the number of backups kept for each plan file.
+const MaxBackups = 20
+
+// backupTimeFormat sorts in time order and is safe in file names.
+const backupTimeFormat = "20060102-150405.000000"
+
+// Backup is an earlier version of a plan file.
+type Backup struct {
+	Path string
+	Time time.Time
+}
+
+// ReadPlan reads and parses the plan file at filename.
+func ReadPlan(filename string) (*models.Plan, error) {
+	planBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, &PlanError{filename, err}
+	}
+
+	var plan models.Plan
+	err = json.Unmarshal(planBytes, &plan)
+	if err != nil {
+		return nil, &PlanError{filename, planParseError(planBytes, err)}
+	}
+
+	return &plan, nil
+}
+
+// WritePlan writes the plan to filename as JSON. The file is replaced
+// atomically and its previous version is kept as a backup.
+func WritePlan(filename string, plan *models.Plan) error {
+	planJson, err := json.Marshal(plan)
+	if err != nil {
+		return &PlanError{filename, err}
+	}
+
+	err = backupPlan(filename, planJson)
+	if err != nil {
+		return &PlanError{filename, err}
+	}
+
+	err = writeFileAtomic(filename, planJson, 0664)
+	if err != nil {
+		return &PlanError{filename, err}
+	}
+
+	return nil
+}
+
+// UpdatePlan reads the plan file, applies the update and writes it back while
+// holding the lock on the file, so that concurrent updates by other processes
+// aren't lost. A missing file is created.
+func UpdatePlan(filename string, update func(plan *models.Plan) error) error {
+	unlock, err := LockPlan(filename)
+	if err != nil {
+		return &PlanError{filename, err}
+	}
+	defer unlock()
+
+	plan, err := ReadPlan(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		plan = &models.Plan{Centers: []*models.CenterWeek{}}
+	} else if err != nil {
+		return err
+	}
+
+	err = update(plan)
+	if err != nil {
+		return err
+	}
+
+	return WritePlan(filename, plan)
+}
+
+// Backups returns the backups of the plan file, the most recent first.
+func Backups(filename string) ([]*Backup, error) {
+	prefix := backupPrefix(filename)
+	matches, err := filepath.Glob(filepath.Join(backupDir(filename), prefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []*Backup{}
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), prefix), ".json")
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, &Backup{Path: m, Time: t})
+	}
+
+	slices.SortFunc(backups, func(a, b *Backup) int {
+		return b.Time.Compare(a.Time)
+	})
+
+	return backups, nil
+}
+
+// RestorePlan replaces the plan file with the backup. The current version is
+// backed up first, so a restore can itself be undone.
+func RestorePlan(filename string, backup *Backup) error {
+	unlock, err := LockPlan(filename)
+	if err != nil {
+		return &PlanError{filename, err}
+	}
+	defer unlock()
+
+	plan, err := ReadPlan(backup.Path)
+	if err != nil {
+		return err
+	}
+
+	return WritePlan(filename, plan)
+}
+
+// backupPlan copies the current plan file into the backup directory unless it
+// doesn't exist or already holds the new contents, and removes the oldest
+// backups beyond MaxBackups.
+func backupPlan(filename string, newContents []byte) error {
+	current, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(current, newContents) {
+		return nil
+	}
+
+	dir := backupDir(filename)
+	err = os.MkdirAll(dir, 0775)
+	if err != nil {
+		return err
+	}
+
+	name := backupPrefix(filename) + time.Now().Format(backupTimeFormat) + ".json"
+	err = writeFileAtomic(filepath.Join(dir, name), current, 0664)
+	if err != nil {
+		return err
+	}
+
+	backups, err := Backups(filename)
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(len(backups), MaxBackups):] {
+		slog.Debug("removing backup", "path", b.Path)
+		err = os.Remove(b.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupDir is .gojoin/backups next to the plan file.
+func backupDir(filename string) string {
+	return filepath.Join(filepath.Dir(filename), ".gojoin", "backups")
+}
+
+func backupPrefix(filename string) string {
+	return strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)) + "."
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory
+// and renames it over filename, so that readers never see a partial file.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempName := f.Name()
+	defer os.Remove(tempName)
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	err = os.Chmod(tempName, perm)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tempName, filename)
+	if err != nil {
+		return fmt.Errorf("replacing %v: %w", filename, err)
+	}
+
+	return nil
+}
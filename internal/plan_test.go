@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+func TestUpdatePlanConcurrently(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plan.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := UpdatePlan(filename, func(plan *models.Plan) error {
+				plan.SetActivities(fmt.Sprintf("person%d", i), "165", "Plant", []*models.Activity{{Id: i + 1}})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	plan, err := ReadPlan(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Persons()) != 10 {
+		t.Errorf("Expected 10 persons but got %v", plan.Persons())
+	}
+}
+
+func TestWritePlanBackups(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plan.json")
+
+	for i := 0; i < MaxBackups+5; i++ {
+		plan := &models.Plan{}
+		plan.AddActivities("Bob", "165", "Plant", []*models.Activity{{Id: i + 1}})
+		err := WritePlan(filename, plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := Backups(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != MaxBackups {
+		t.Fatalf("Expected %v backups but got %v", MaxBackups, len(backups))
+	}
+
+	err = RestorePlan(filename, backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := ReadPlan(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := plan.Centers[0].Events[0].Id; id != MaxBackups+4 {
+		t.Errorf("Expected the previous version with activity %v but got %v", MaxBackups+4, id)
+	}
+}
+
+func TestPlanSchemaVersion(t *testing.T) {
+	var schema struct {
+		Properties struct {
+			SchemaVersion struct {
+				Const int `json:"const"`
+			} `json:"schema_version"`
+		} `json:"properties"`
+	}
+	err := json.Unmarshal(PlanSchema, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Properties.SchemaVersion.Const != models.SchemaVersion {
+		t.Errorf("Expected the schema for version %v but got %v", models.SchemaVersion, schema.Properties.SchemaVersion.Const)
+	}
+}
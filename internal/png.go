@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+
+	"github.com/snocorp/gojoin/models"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// RenderPNG rasterises the view to a PNG image. The layout is the same one
+// used by RenderSVG.
+func RenderPNG(w io.Writer, view *models.View, options CanvasOptions) error {
+	options = options.withDefaults()
+	c := newCanvas(view, options)
+
+	regular, bold, err := loadFonts(options.FontPath)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, options.Width, options.Height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	black := color.RGBA{0, 0, 0, 255}
+	grey := color.RGBA{102, 102, 102, 255}
+	light := color.RGBA{221, 221, 221, 255}
+
+	for _, l := range c.Lines {
+		fillRect(img, rect{l.X, l.Y, l.W, 1}, light)
+	}
+
+	for _, d := range c.Days {
+		strokeRect(img, d, black)
+	}
+
+	faces := map[float64]font.Face{}
+	face := func(f *opentype.Font, size float64) (font.Face, error) {
+		key := size
+		if f == bold {
+			key = -size
+		}
+		if fc, ok := faces[key]; ok {
+			return fc, nil
+		}
+		fc, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		faces[key] = fc
+		return fc, nil
+	}
+
+	for _, t := range c.Labels {
+		f := regular
+		if t.Bold {
+			f = bold
+		}
+		fc, err := face(f, t.Size)
+		if err != nil {
+			return err
+		}
+		drawText(img, fc, t.X, t.Y, t.Text, img.Bounds().Max.X, black)
+	}
+
+	eventFace, err := face(regular, options.FontSize)
+	if err != nil {
+		return err
+	}
+	for _, e := range c.Events {
+		fill, err := parseColour(e.Fill)
+		if err != nil {
+			return err
+		}
+		fillRect(img, e.rect, fill)
+		strokeRect(img, e.rect, grey)
+
+		clip := img.SubImage(image.Rect(int(e.X), int(e.Y), int(e.X+e.W), int(e.Y+e.H))).(*image.RGBA)
+		for j, line := range e.Lines {
+			drawText(clip, eventFace, e.X+2, e.Y+options.FontSize*float64(j+1), line, int(e.X+e.W), black)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func loadFonts(fontPath string) (regular *opentype.Font, bold *opentype.Font, err error) {
+	if fontPath != "" {
+		fontBytes, err := os.ReadFile(fontPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		regular, err = opentype.Parse(fontBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return regular, regular, nil
+	}
+
+	regular, err = opentype.Parse(goregular.TTF)
+	if err != nil {
+		return
+	}
+	bold, err = opentype.Parse(gobold.TTF)
+	return
+}
+
+func pixelRect(r rect) image.Rectangle {
+	return image.Rect(
+		int(math.Round(r.X)),
+		int(math.Round(r.Y)),
+		int(math.Round(r.X+r.W)),
+		int(math.Round(r.Y+r.H)),
+	)
+}
+
+func fillRect(img draw.Image, r rect, c color.Color) {
+	draw.Draw(img, pixelRect(r), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func strokeRect(img draw.Image, r rect, c color.Color) {
+	fillRect(img, rect{r.X, r.Y, r.W, 1}, c)
+	fillRect(img, rect{r.X, r.Y + r.H - 1, r.W, 1}, c)
+	fillRect(img, rect{r.X, r.Y, 1, r.H}, c)
+	fillRect(img, rect{r.X + r.W - 1, r.Y, 1, r.H}, c)
+}
+
+// drawText draws a single line of text, truncating it so that it doesn't
+// extend past maxX.
+func drawText(img draw.Image, face font.Face, x, y float64, text string, maxX int, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(int(x), int(y)),
+	}
+
+	limit := fixed.I(maxX)
+	for _, r := range text {
+		advance, ok := face.GlyphAdvance(r)
+		if ok && d.Dot.X+advance > limit {
+			break
+		}
+		d.DrawString(string(r))
+	}
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+func TestRenderPNG(t *testing.T) {
+	plan, err := ReadPlan("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := models.NewView(plan.CenterPlan(), models.Ordering{Centers: models.OrderName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fontPath := filepath.Join(t.TempDir(), "mono.ttf")
+	err = os.WriteFile(fontPath, gomono.TTF, 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, options := range []CanvasOptions{{}, {Width: 640, Height: 480, FontPath: fontPath}} {
+		var buf bytes.Buffer
+		err = RenderPNG(&buf, view, options)
+		if err != nil {
+			t.Fatalf("Expected a PNG with %+v but got %v", options, err)
+		}
+
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("Expected a decodable PNG with %+v but got %v", options, err)
+		}
+
+		options = options.withDefaults()
+		bounds := img.Bounds()
+		if bounds.Dx() != options.Width || bounds.Dy() != options.Height {
+			t.Errorf("Expected %vx%v but got %vx%v", options.Width, options.Height, bounds.Dx(), bounds.Dy())
+		}
+	}
+
+	_, _, err = loadFonts(filepath.Join(t.TempDir(), "missing.ttf"))
+	if err == nil {
+		t.Errorf("Expected an error for a missing font")
+	}
+}
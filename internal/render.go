@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"html/template"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+const DefaultWeekTemplate = "./templates/week.html.gotmpl"
+
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"css": func(s string) template.CSS {
+			return template.CSS(s)
+		},
+		"join": strings.Join,
+	}
+}
+
+// DecorateView adds to an HTML view of the plan what comes from the config:
+// the cost of the selected activities when any of their fees is known, the
+// conflicts between the selected and shortlisted activities, allowing for the
+// travel times, and the shading of the times at which none of the persons, or
+// no driver, is available.
+func DecorateView(view *models.View, plan *models.Plan, config *Config, travel *models.TravelTimes) error {
+	report := models.NewCostReport(plan, config.Cost.Options())
+	if report.Family.Unknown < report.Family.Activities {
+		view.Cost = report
+	}
+
+	conflicts, err := models.FindConflicts(plan, []models.Selection{models.Selected, models.Shortlisted}, travel)
+	if err != nil {
+		return err
+	}
+	view.MarkConflicts(conflicts)
+
+	return shadeUnavailable(view, plan, config)
+}
+
+// shadeUnavailable shades the times at which none of the persons of the plan,
+// or no driver, is available. Persons without a configured availability are
+// always available.
+func shadeUnavailable(view *models.View, plan *models.Plan, config *Config) error {
+	configured := false
+	persons := []models.Availability{}
+	for _, person := range plan.Persons() {
+		availability, ok, err := config.PersonAvailability(person)
+		if err != nil {
+			return err
+		}
+		configured = configured || ok
+		persons = append(persons, availability)
+	}
+
+	drivers, driversOk, err := config.PersonAvailability(models.DriversKey)
+	if err != nil {
+		return err
+	}
+
+	if configured || driversOk {
+		view.ShadeUnavailable(persons, drivers)
+	}
+	return nil
+}
+
+// RenderHTML executes the HTML template at filename using the view.
+func RenderHTML(w io.Writer, view *models.View, filename string) error {
+	name := path.Base(filename)
+	tmpl, err := template.New(name).Funcs(templateFuncs()).ParseFiles(filename)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, view)
+}
+
+// WriteOutput replaces filename with the rendered output, so that a view that
+// fails to render never leaves a partial file behind.
+func WriteOutput(filename string, data []byte) error {
+	return writeFileAtomic(filename, data, 0664)
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func checkGolden(t *testing.T, golden string, actual []byte) {
+	goldenPath := filepath.Join("testdata", golden)
+	if *update {
+		err := os.WriteFile(goldenPath, actual, 0664)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("Output does not match %v, run the tests with -update to see the differences", goldenPath)
+	}
+}
+
+func TestRenderHTMLGolden(t *testing.T) {
+	plan, err := ReadPlan("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := models.NewView(plan.CenterPlan(), models.Ordering{Centers: models.OrderName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = RenderHTML(&buf, view, "../templates/week.html.gotmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "week.golden.html", buf.Bytes())
+}
+
+// decoratedPlan returns the test plan with a fee and a shortlisted activity
+// that overlaps a selected one, and a config with a budget and availability.
+func decoratedPlan(t *testing.T) (*models.Plan, *Config) {
+	plan, err := ReadPlan("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cw := range plan.Centers {
+		for _, e := range cw.Events {
+			switch e.Id {
+			case 10:
+				e.Selections = map[string]models.Selection{"Bob": models.Shortlisted}
+			case 11:
+				fee := models.Dollars(84)
+				e.Fee = &fee
+			}
+		}
+	}
+
+	config := &Config{
+		Cost: CostConfig{Budget: 50},
+		Availability: map[string][]WindowConfig{
+			"Ann": {{Days: []string{"weekdays"}, From: "3:00 PM", To: "6:00 PM"}},
+			"Bob": {{Days: []string{"weekends"}, From: "9:00 AM", To: "11:00 AM"}},
+		},
+	}
+
+	return plan, config
+}
+
+func TestDecorateViewGolden(t *testing.T) {
+	plan, config := decoratedPlan(t)
+
+	view, err := models.NewView(plan.CenterPlan(), models.Ordering{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DecorateView(view, plan, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = RenderHTML(&buf, view, "../templates/week.html.gotmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "week.decorated.golden.html", buf.Bytes())
+}
@@ -0,0 +1,8 @@
+package internal
+
+import _ "embed"
+
+// PlanSchema is the JSON Schema of the plan file at models.SchemaVersion.
+//
+//go:embed plan.schema.json
+var PlanSchema []byte
@@ -0,0 +1,290 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+type ServerOptions struct {
+	InputPath      string
+	WeekTemplate   string
+	AgendaTemplate string
+	Ordering       models.Ordering
+	// Config and Travel decorate the week views like gojoin view, see
+	// DecorateView.
+	Config *Config
+	Travel *models.TravelTimes
+	// PollInterval is how often the plan file and templates are checked for
+	// changes.
+	PollInterval time.Duration
+}
+
+// Server renders the plan file on request and notifies connected browsers
+// when the plan or the templates change.
+type Server struct {
+	options ServerOptions
+	mux     *http.ServeMux
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]bool
+}
+
+const liveReloadScript = `<script>
+  new EventSource("/events").addEventListener("reload", function () {
+    window.location.reload();
+  });
+</script>
+`
+
+func NewServer(options ServerOptions) *Server {
+	if options.WeekTemplate == "" {
+		options.WeekTemplate = DefaultWeekTemplate
+	}
+	if options.AgendaTemplate == "" {
+		options.AgendaTemplate = DefaultAgendaTemplate
+	}
+	if options.Config == nil {
+		options.Config = &Config{}
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = 500 * time.Millisecond
+	}
+
+	s := &Server{
+		options:     options,
+		mux:         http.NewServeMux(),
+		subscribers: map[chan struct{}]bool{},
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleWeek)
+	s.mux.HandleFunc("GET /centers/{id}", s.handleCenter)
+	s.mux.HandleFunc("GET /persons/{person}", s.handlePerson)
+	s.mux.HandleFunc("GET /agenda", s.handleAgenda)
+	s.mux.HandleFunc("GET /events", s.handleEvents)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Handle registers an additional handler on the server.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Watch polls the plan file and templates until the context is cancelled and
+// tells every connected browser to reload when one of them changes.
+func (s *Server) Watch(ctx context.Context) {
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+
+	last := s.modTimes()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := s.modTimes()
+			if current != last {
+				slog.Debug("change detected, reloading")
+				last = current
+				s.Notify()
+			}
+		}
+	}
+}
+
+// Notify tells every connected browser to reload.
+func (s *Server) Notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// modTimes summarises the modification times of the watched files.
+func (s *Server) modTimes() string {
+	files := []string{s.options.InputPath}
+	for _, dir := range []string{filepath.Dir(s.options.WeekTemplate), filepath.Dir(s.options.AgendaTemplate)} {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.gotmpl"))
+		if err == nil {
+			files = append(files, matches...)
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:missing;", f)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", f, info.ModTime().UnixNano(), info.Size())
+	}
+
+	return b.String()
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.subscribers[ch] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) readPlan(w http.ResponseWriter) (*models.Plan, bool) {
+	plan, err := ReadPlan(s.options.InputPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return plan, true
+}
+
+func (s *Server) handleWeek(w http.ResponseWriter, r *http.Request) {
+	plan, ok := s.readPlan(w)
+	if !ok {
+		return
+	}
+
+	s.renderWeek(w, plan, plan.CenterPlan())
+}
+
+func (s *Server) handleCenter(w http.ResponseWriter, r *http.Request) {
+	plan, ok := s.readPlan(w)
+	if !ok {
+		return
+	}
+
+	centerPlan := plan.CenterPlan().ForCenter(r.PathValue("id"))
+	if len(centerPlan.Plans) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.renderWeek(w, plan, centerPlan)
+}
+
+func (s *Server) handlePerson(w http.ResponseWriter, r *http.Request) {
+	plan, ok := s.readPlan(w)
+	if !ok {
+		return
+	}
+
+	personPlan := plan.ForPerson(r.PathValue("person"))
+	if len(personPlan.Centers) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.renderWeek(w, personPlan, personPlan.CenterPlan())
+}
+
+func (s *Server) handleAgenda(w http.ResponseWriter, r *http.Request) {
+	plan, ok := s.readPlan(w)
+	if !ok {
+		return
+	}
+
+	if person := r.URL.Query().Get("person"); person != "" {
+		plan = plan.ForPerson(person)
+	}
+
+	agenda, err := models.NewAgenda(plan, r.URL.Query().Get("group-by"), s.options.Ordering)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.renderHTML(w, func(out io.Writer) error {
+		return renderAgendaHTML(out, agenda, s.options.AgendaTemplate)
+	})
+}
+
+// renderWeek renders the week view of the center plan, decorated from the
+// plan it is part of.
+func (s *Server) renderWeek(w http.ResponseWriter, plan *models.Plan, centerPlan *models.CenterPlan) {
+	view, err := models.NewView(centerPlan, s.options.Ordering)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = DecorateView(view, plan, s.options.Config, s.options.Travel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.renderHTML(w, func(out io.Writer) error {
+		return RenderHTML(out, view, s.options.WeekTemplate)
+	})
+}
+
+// renderHTML renders a page into a buffer so that errors can be reported and
+// the live reload script can be added before the closing body tag.
+func (s *Server) renderHTML(w http.ResponseWriter, render func(io.Writer) error) {
+	var buf bytes.Buffer
+	err := render(&buf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := buf.Bytes()
+	if i := bytes.LastIndex(page, []byte("</body>")); i >= 0 {
+		page = append(page[:i:i], append([]byte(liveReloadScript), page[i:]...)...)
+	} else {
+		page = append(page, liveReloadScript...)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
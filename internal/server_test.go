@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	data, err := os.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputPath := filepath.Join(t.TempDir(), "plan.json")
+	err = os.WriteFile(inputPath, data, 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewServer(ServerOptions{
+		InputPath:      inputPath,
+		WeekTemplate:   "../templates/week.html.gotmpl",
+		AgendaTemplate: "../templates/agenda.html.gotmpl",
+		PollInterval:   10 * time.Millisecond,
+	}), inputPath
+}
+
+func TestServerRoutes(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/", http.StatusOK, "Plant Rec"},
+		{"/centers/384", http.StatusOK, "Pinecrest"},
+		{"/centers/999", http.StatusNotFound, ""},
+		{"/persons/Ann", http.StatusOK, "Bronze Star"},
+		{"/persons/Zed", http.StatusNotFound, ""},
+		{"/agenda?group-by=center", http.StatusOK, "Activity Agenda"},
+		{"/agenda?group-by=colour", http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest("GET", test.path, nil))
+
+		if rec.Code != test.status {
+			t.Errorf("Expected %v for %v but got %v", test.status, test.path, rec.Code)
+			continue
+		}
+		body := rec.Body.String()
+		if test.status == http.StatusOK && (!strings.Contains(body, test.contains) || !strings.Contains(body, liveReloadScript)) {
+			t.Errorf("Expected %v with the live reload script for %v but got %v", test.contains, test.path, body)
+		}
+	}
+}
+
+func TestServerReload(t *testing.T) {
+	server, inputPath := newTestServer(t)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go server.Watch(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// the browser is subscribed once the handler has registered it
+	for {
+		server.mu.Lock()
+		subscribed := len(server.subscribers) > 0
+		server.mu.Unlock()
+		if subscribed {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatal("Expected the events request to subscribe")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the plan keeps changing in case the watcher hadn't started polling
+	go func() {
+		for ctx.Err() == nil {
+			f, err := os.OpenFile(inputPath, os.O_APPEND|os.O_WRONLY, 0664)
+			if err == nil {
+				io.WriteString(f, "\n")
+				f.Close()
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Expected a reload event but got %v", err)
+	}
+	if line != "event: reload\n" {
+		t.Errorf("Expected a reload event but got %q", line)
+	}
+}
+
+func TestServerDecoratesWeek(t *testing.T) {
+	plan, config := decoratedPlan(t)
+	inputPath := filepath.Join(t.TempDir(), "plan.json")
+	err := WritePlan(inputPath, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(ServerOptions{
+		InputPath:    inputPath,
+		WeekTemplate: "../templates/week.html.gotmpl",
+		Config:       config,
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected %v but got %v", http.StatusOK, rec.Code)
+	}
+
+	// the page served is the one gojoin view writes
+	expected, err := os.ReadFile("testdata/week.decorated.golden.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := strings.Replace(rec.Body.String(), liveReloadScript, "", 1)
+	if body != string(expected) {
+		t.Errorf("Expected the decorated week view but got %v", body)
+	}
+}
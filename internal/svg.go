@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+// RenderSVG writes the view as a standalone SVG document.
+func RenderSVG(w io.Writer, view *models.View, options CanvasOptions) error {
+	options = options.withDefaults()
+	c := newCanvas(view, options)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s" font-size="%v">`+"\n",
+		options.Width, options.Height, options.Width, options.Height, html.EscapeString(options.FontFamily), options.FontSize)
+	fmt.Fprintf(b, `<rect x="0" y="0" width="%d" height="%d" fill="white"/>`+"\n", options.Width, options.Height)
+
+	for _, d := range c.Days {
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="black"/>`+"\n", d.X, d.Y, d.W, d.H)
+	}
+
+	for _, l := range c.Lines {
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", l.X, l.Y, l.X+l.W, l.Y+l.H)
+	}
+
+	for _, t := range c.Labels {
+		weight := "normal"
+		if t.Bold {
+			weight = "bold"
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="%v" font-weight="%s">%s</text>`+"\n", t.X, t.Y, t.Size, weight, html.EscapeString(t.Text))
+	}
+
+	for i, e := range c.Events {
+		clipId := fmt.Sprintf("event%d", i)
+		if e.Href != "" {
+			fmt.Fprintf(b, `<a xlink:href="%s" href="%s" target="_blank">`+"\n", html.EscapeString(e.Href), html.EscapeString(e.Href))
+		}
+		fmt.Fprintf(b, `<clipPath id="%s"><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"/></clipPath>`+"\n", clipId, e.X, e.Y, e.W, e.H)
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="3" fill="%s" stroke="#666"/>`+"\n", e.X, e.Y, e.W, e.H, html.EscapeString(e.Fill))
+		fmt.Fprintf(b, `<text clip-path="url(#%s)">`, clipId)
+		for j, line := range e.Lines {
+			fmt.Fprintf(b, `<tspan x="%.1f" y="%.1f">%s</tspan>`, e.X+2, e.Y+options.FontSize*float64(j+1), html.EscapeString(line))
+		}
+		fmt.Fprint(b, "</text>\n")
+		if e.Href != "" {
+			fmt.Fprint(b, "</a>\n")
+		}
+	}
+
+	fmt.Fprint(b, "</svg>\n")
+
+	return b.Flush()
+}
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+func TestRenderSVGGolden(t *testing.T) {
+	plan, err := ReadPlan("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := models.NewView(plan.CenterPlan(), models.Ordering{Centers: models.OrderName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = RenderSVG(&buf, view, CanvasOptions{Width: 800, Height: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "week.golden.svg", buf.Bytes())
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// Table is tabular output that can be written aligned for the terminal or as
+// CSV.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+func (t *Table) Append(row ...string) {
+	t.Rows = append(t.Rows, row)
+}
+
+// Write writes the table in the given format, table or csv.
+func (t *Table) Write(w io.Writer, format string) error {
+	switch format {
+	case "", FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+		for _, row := range t.Rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		err := cw.Write(t.Header)
+		if err != nil {
+			return err
+		}
+		err = cw.WriteAll(t.Rows)
+		if err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unknown table format %v", format)
+}
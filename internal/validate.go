@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+// Issue is a problem of a plan file with its position in the file. The line
+// and column are 0 when unknown.
+type Issue struct {
+	models.Problem
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Validation is the result of checking a plan file. Plan is nil when the file
+// couldn't be parsed.
+type Validation struct {
+	Path   string
+	Plan   *models.Plan
+	Issues []*Issue
+}
+
+// ValidatePlan checks the plan file at filename. An error is only returned
+// when the file can't be read.
+func ValidatePlan(filename string) (*Validation, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, &PlanError{filename, err}
+	}
+
+	validation := &Validation{Path: filename, Issues: []*Issue{}}
+
+	var plan models.Plan
+	err = json.Unmarshal(data, &plan)
+	if err != nil {
+		line, column := errorPosition(data, err)
+		validation.Issues = append(validation.Issues, &Issue{
+			Problem: models.Problem{Message: err.Error()},
+			Line:    line,
+			Column:  column,
+		})
+		return validation, nil
+	}
+	validation.Plan = &plan
+
+	offsets := jsonOffsets(data)
+	version, err := models.PlanVersion(data)
+	if err == nil && version < models.SchemaVersion {
+		path := "schema_version"
+		if _, ok := offsets[path]; !ok {
+			path = ""
+		}
+		line, column := position(data, offsets[path])
+		validation.Issues = append(validation.Issues, &Issue{
+			Problem: models.Problem{
+				Path:    path,
+				Message: fmt.Sprintf("plan uses schema version %v, the current version is %v", version, models.SchemaVersion),
+				Fixable: true,
+			},
+			Line:   line,
+			Column: column,
+		})
+	}
+
+	for _, problem := range plan.Problems() {
+		issue := &Issue{Problem: *problem}
+		if offset, ok := offsets[problem.Path]; ok {
+			issue.Line, issue.Column = position(data, offset)
+		}
+		validation.Issues = append(validation.Issues, issue)
+	}
+
+	return validation, nil
+}
+
+func (i *Issue) String() string {
+	if i.Line == 0 {
+		return i.Problem.String()
+	}
+	return fmt.Sprintf("%d:%d: %v", i.Line, i.Column, i.Problem.String())
+}
+
+// planParseError adds the position in the file to JSON syntax and type
+// errors.
+func planParseError(data []byte, err error) error {
+	line, column := errorPosition(data, err)
+	if line == 0 {
+		return err
+	}
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
+
+// errorPosition returns the line and column of a JSON syntax or type error,
+// or 0 when it has no position.
+func errorPosition(data []byte, err error) (int, int) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return position(data, int(syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		// the offset is the end of the value
+		return position(data, int(typeErr.Offset))
+	}
+
+	return 0, 0
+}
+
+// position converts a byte offset into a line and a column, starting at 1.
+func position(data []byte, offset int) (int, int) {
+	offset = min(max(offset, 0), len(data))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// jsonOffsets returns the offset at which each value of the JSON document
+// starts, by path: the root is "", then centers, centers[0],
+// centers[0].center_id and so on. The offsets are incomplete for invalid
+// JSON.
+func jsonOffsets(data []byte) map[string]int {
+	type frame struct {
+		path      string
+		array     bool
+		index     int
+		key       string
+		expectKey bool
+	}
+
+	offsets := map[string]int{}
+	stack := []*frame{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := int(decoder.InputOffset())
+		for offset < len(data) && bytes.IndexByte([]byte(" \t\r\n,:"), data[offset]) >= 0 {
+			offset++
+		}
+
+		token, err := decoder.Token()
+		if err != nil {
+			return offsets
+		}
+
+		delim, isDelim := token.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && !stack[len(stack)-1].array {
+				stack[len(stack)-1].expectKey = true
+			}
+			continue
+		}
+
+		path := ""
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if !top.array && top.expectKey {
+				top.key, _ = token.(string)
+				top.expectKey = false
+				continue
+			}
+
+			if top.array {
+				top.index++
+				path = top.path + "[" + strconv.Itoa(top.index) + "]"
+			} else if top.path == "" {
+				path = top.key
+			} else {
+				path = top.path + "." + top.key
+			}
+		}
+		offsets[path] = offset
+
+		switch {
+		case isDelim && delim == '{':
+			stack = append(stack, &frame{path: path, expectKey: true})
+		case isDelim && delim == '[':
+			stack = append(stack, &frame{path: path, array: true, index: -1})
+		case len(stack) > 0 && !stack[len(stack)-1].array:
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+}
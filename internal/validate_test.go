@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidatePlan(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plan.json")
+	data := `{"centers":[
+  {"center_id":"165","center_name":"Plant","events":[
+    {"id":1,"name":"Swim","time_range":"9:45 - 10","days_of_week":"Sun","persons":["Bob"]}
+  ]}
+]}`
+	err := os.WriteFile(filename, []byte(data), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validation, err := ValidatePlan(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(validation.Issues) != 1 {
+		t.Fatalf("Expected a single issue but got %v", validation.Issues)
+	}
+
+	issue := validation.Issues[0]
+	if issue.Path != "centers[0].events[0].time_range" || issue.Line != 3 || issue.Column != 40 {
+		t.Errorf("Expected the time range at 3:40 but got %v at %v:%v", issue.Path, issue.Line, issue.Column)
+	}
+}
+
+func TestValidatePlanSyntaxError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plan.json")
+	err := os.WriteFile(filename, []byte("{\"centers\":[\n  {\"center_id\":\"165\",]}"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validation, err := ValidatePlan(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if validation.Plan != nil {
+		t.Errorf("Expected no plan for invalid JSON")
+	}
+	if len(validation.Issues) != 1 || validation.Issues[0].Line != 2 {
+		t.Errorf("Expected a single issue on line 2 but got %v", validation.Issues)
+	}
+
+	_, err = ReadPlan(filename)
+	if err == nil {
+		t.Errorf("Expected an error reading the plan")
+	}
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+// DefaultWatchStatePath is where gojoin watch keeps the openings it has seen
+// between runs.
+const DefaultWatchStatePath = ".gojoin/watch.json"
+
+const DefaultWatchInterval = 5 * time.Minute
+
+// WatchTarget is a search whose activities are watched. When Ids is set, only
+// those activities of the results are watched.
+type WatchTarget struct {
+	Name       string
+	Request    models.ActivityRequest
+	CenterName string
+	Ids        []int
+}
+
+type WatchOptions struct {
+	// BaseUrl is the ActiveNet site to search, DefaultBaseUrl when empty.
+	BaseUrl   string
+	Targets   []*WatchTarget
+	Interval  time.Duration
+	StatePath string
+	// HistoryPath is the openings history each poll is appended to, none
+	// when empty.
+	HistoryPath string
+	Notifiers   []Notifier
+}
+
+// OpeningState is the last seen openings of an activity.
+type OpeningState struct {
+	Name      string    `json:"name"`
+	Openings  string    `json:"openings"`
+	Count     int       `json:"count"`
+	Waitlist  int       `json:"wait_list_count,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WatchState is stored between runs, keyed by activity ID.
+type WatchState struct {
+	Activities map[string]*OpeningState `json:"activities"`
+}
+
+// Opening is a watched activity that went from full to available.
+type Opening struct {
+	Target   string           `json:"target"`
+	Activity *models.Activity `json:"activity"`
+	Previous string           `json:"previous"`
+	Current  string           `json:"current"`
+	Time     time.Time        `json:"time"`
+}
+
+func (o *Opening) Summary() string {
+	return fmt.Sprintf("%v (%v, %v %v) has openings: %v, was %v",
+		o.Activity.Name, o.Activity.Number, o.Activity.DayOfWeek, o.Activity.TimeRange, o.Current, o.Previous)
+}
+
+// Watcher polls the targets and notifies when activities open up.
+type Watcher struct {
+	options WatchOptions
+	state   *WatchState
+}
+
+func NewWatcher(options WatchOptions) (*Watcher, error) {
+	if options.Interval <= 0 {
+		options.Interval = DefaultWatchInterval
+	}
+	if options.StatePath == "" {
+		options.StatePath = DefaultWatchStatePath
+	}
+
+	state := &WatchState{Activities: map[string]*OpeningState{}}
+	data, err := os.ReadFile(options.StatePath)
+	if err == nil {
+		err = json.Unmarshal(data, state)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", options.StatePath, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if state.Activities == nil {
+		state.Activities = map[string]*OpeningState{}
+	}
+
+	return &Watcher{options: options, state: state}, nil
+}
+
+// Run polls until the context is cancelled. Failed polls are logged and
+// retried at the next interval.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.Poll(ctx)
+		if err != nil {
+			slog.Warn("poll failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll searches each target once, notifies about the activities that went
+// from full to available and saves the state. Activities seen for the first
+// time are only recorded.
+func (w *Watcher) Poll(ctx context.Context) ([]*Opening, error) {
+	openings := []*Opening{}
+	var errs []error
+	for _, target := range w.options.Targets {
+		activities, err := GetActivities(target.Request, GetActivitiesOptions{BaseUrl: w.options.BaseUrl})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", target.Name, err))
+			continue
+		}
+
+		if w.options.HistoryPath != "" {
+			snapshots := Snapshots(activities, target.Request, target.CenterName, time.Now())
+			err = AppendSnapshots(w.options.HistoryPath, snapshots)
+			if err != nil {
+				slog.Warn("couldn't record openings", "path", w.options.HistoryPath, "error", err)
+			}
+		}
+
+		for _, a := range activities {
+			if len(target.Ids) > 0 && !slices.Contains(target.Ids, a.Id) {
+				continue
+			}
+
+			opening, err := w.update(target, a)
+			if err != nil {
+				slog.Warn("skipping activity", "id", a.Id, "error", err)
+				continue
+			}
+			if opening != nil {
+				openings = append(openings, opening)
+			}
+		}
+	}
+
+	for _, o := range openings {
+		for _, n := range w.options.Notifiers {
+			err := n.Notify(ctx, o)
+			if err != nil {
+				slog.Warn("notification failed", "id", o.Activity.Id, "error", err)
+			}
+		}
+	}
+
+	err := w.save()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return openings, errors.Join(errs...)
+}
+
+func (w *Watcher) update(target *WatchTarget, a *models.Activity) (*Opening, error) {
+	count, err := a.OpeningsCount()
+	if err != nil {
+		return nil, err
+	}
+
+	key := strconv.Itoa(a.Id)
+	previous, seen := w.state.Activities[key]
+	now := time.Now()
+	w.state.Activities[key] = &OpeningState{
+		Name:      a.Name,
+		Openings:  a.Openings,
+		Count:     count,
+		Waitlist:  a.Waitlist,
+		UpdatedAt: now,
+	}
+	slog.Debug("watched", "id", a.Id, "name", a.Name, "openings", a.Openings, "waitlist", a.Waitlist)
+
+	if !seen || previous.Count > 0 || count == 0 {
+		return nil, nil
+	}
+
+	return &Opening{
+		Target:   target.Name,
+		Activity: a,
+		Previous: previous.Openings,
+		Current:  a.Openings,
+		Time:     now,
+	}, nil
+}
+
+func (w *Watcher) save() error {
+	data, err := json.MarshalIndent(w.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(w.options.StatePath), 0775)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(w.options.StatePath, data, 0664)
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/snocorp/gojoin/models"
+)
+
+type recordingNotifier struct {
+	openings []*Opening
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, opening *Opening) error {
+	n.openings = append(n.openings, opening)
+	return nil
+}
+
+func TestWatcherPoll(t *testing.T) {
+	openings := "Full"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.ActivitySearchResponse{
+			Headers: &models.ActivitySearchHeaders{PageInfo: &models.ActivityPageInfo{PageNumber: 1, TotalPages: 1}},
+			Body: &models.ActivitySearchBody{ActivityItems: []*models.Activity{
+				{Id: 1, Name: "Swim Kids 2", Openings: openings},
+				{Id: 2, Name: "Swim Kids 3", Openings: "Full"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history", "openings.jsonl")
+	notifier := &recordingNotifier{}
+	options := WatchOptions{
+		BaseUrl:     server.URL,
+		Targets:     []*WatchTarget{{Name: "swim", Ids: []int{1}}},
+		StatePath:   filepath.Join(dir, "watch.json"),
+		HistoryPath: historyPath,
+		Notifiers:   []Notifier{notifier},
+	}
+
+	watcher, err := NewWatcher(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.openings) != 0 {
+		t.Errorf("Expected no notification for a full activity but got %v", notifier.openings)
+	}
+
+	// the state is kept between runs
+	watcher, err = NewWatcher(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openings = "3"
+	_, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.openings) != 1 || notifier.openings[0].Activity.Id != 1 || notifier.openings[0].Previous != "Full" {
+		t.Fatalf("Expected a notification for activity 1 but got %v", notifier.openings)
+	}
+
+	openings = "2"
+	_, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.openings) != 1 {
+		t.Errorf("Expected no notification while openings remain but got %v", notifier.openings)
+	}
+
+	// every activity of every poll is recorded
+	snapshots, err := ReadSnapshots(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 6 {
+		t.Fatalf("Expected 6 snapshots but got %v", len(snapshots))
+	}
+	if snapshots[4].Id != 1 || snapshots[4].Openings != "2" || snapshots[4].Count != 2 {
+		t.Errorf("Expected 2 openings for activity 1 but got %+v", snapshots[4])
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Opening
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&received)
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{Url: server.URL}
+	err := notifier.Notify(context.Background(), &Opening{Activity: &models.Activity{Id: 7}, Current: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Activity == nil || received.Activity.Id != 7 || received.Current != "1" {
+		t.Errorf("Expected the opening of activity 7 but got %v", received)
+	}
+}
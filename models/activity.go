@@ -1,9 +1,12 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +16,23 @@ const (
 	PM      = 2
 )
 
+type Selection string
+
+const (
+	NotSelected Selection = ""
+	Shortlisted Selection = "shortlisted"
+	Selected    Selection = "selected"
+)
+
+func ParseSelection(s string) (Selection, error) {
+	switch Selection(s) {
+	case NotSelected, Shortlisted, Selected:
+		return Selection(s), nil
+	}
+
+	return NotSelected, fmt.Errorf("unexpected selection %v", s)
+}
+
 type Activity struct {
 	Id        int    `json:"id"`
 	Name      string `json:"name"`       // "Swim Creatures 4 - Nigig | Otter"
@@ -21,10 +41,89 @@ type Activity struct {
 	DetailUrl string `json:"detail_url"`
 	DayOfWeek string `json:"days_of_week"` // "Sun"
 
+	// Openings is the number of spots left as ActiveNet shows it: a number,
+	// "Full" or a waiting list label. Waitlist is the number of persons on
+	// the waiting list, when given.
+	Openings string `json:"openings,omitempty"`
+	Waitlist int    `json:"wait_list_count,omitempty"`
+
+	// Fee is the fee of the activity for one person, nil when unknown.
+	Fee *Money `json:"fee,omitempty"`
+
+	// Persons are the persons the activity has been loaded for, and
+	// Selections their selection state when it has been set.
+	Persons    []string             `json:"persons,omitempty"`
+	Selections map[string]Selection `json:"selections,omitempty"`
+
 	startTime *TimeOfDay
 	endTime   *TimeOfDay
 }
 
+// UnmarshalJSON reads the fee with parseFee, so that a fee ActiveNet gives no
+// amount for is unknown rather than free.
+func (a *Activity) UnmarshalJSON(data []byte) error {
+	type activity Activity
+	decoded := struct {
+		*activity
+		Fee json.RawMessage `json:"fee"`
+	}{activity: (*activity)(a)}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	a.Fee, err = parseFee(decoded.Fee)
+	return err
+}
+
+func (a *Activity) HasPerson(person string) bool {
+	return slices.Contains(a.Persons, person)
+}
+
+func (a *Activity) AddPerson(person string) {
+	if !a.HasPerson(person) {
+		a.Persons = append(a.Persons, person)
+	}
+}
+
+func (a *Activity) RemovePerson(person string) {
+	a.Persons = slices.DeleteFunc(a.Persons, func(p string) bool { return p == person })
+	delete(a.Selections, person)
+}
+
+func (a *Activity) SelectionFor(person string) Selection {
+	return a.Selections[person]
+}
+
+func (a *Activity) SetSelection(person string, selection Selection) {
+	if selection == NotSelected {
+		delete(a.Selections, person)
+		return
+	}
+
+	if a.Selections == nil {
+		a.Selections = map[string]Selection{}
+	}
+	a.Selections[person] = selection
+}
+
+// OpeningsCount parses Openings into the number of spots left, 0 when the
+// activity is full or only has a waiting list.
+func (a *Activity) OpeningsCount() (int, error) {
+	openings := strings.TrimSpace(a.Openings)
+	n, err := strconv.Atoi(openings)
+	if err == nil {
+		return max(n, 0), nil
+	}
+
+	lower := strings.ToLower(openings)
+	if lower == "full" || strings.Contains(lower, "wait") {
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("unexpected openings %q", a.Openings)
+}
+
 func (a *Activity) Overlaps(other *Activity) (result bool, err error) {
 	st, err := a.StartTime()
 	if err != nil {
@@ -97,6 +196,9 @@ func (a *Activity) Weekday() (time.Weekday, error) {
 func (a *Activity) parseTimeRange() error {
 	re := regexp.MustCompile(`^(?:(\d{1,2}):(\d{1,2}) (AM|PM)|(Noon)) - (?:(\d{1,2}):(\d{1,2}) (AM|PM)|(Noon))$`)
 	matches := re.FindSubmatch([]byte(a.TimeRange))
+	if matches == nil {
+		return fmt.Errorf("unable to parse time range %q", a.TimeRange)
+	}
 
 	startTimeType := UNKNOWN
 	endTimeType := UNKNOWN
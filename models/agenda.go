@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	AgendaByPerson = "person"
+	AgendaByCenter = "center"
+	AgendaByLevel  = "level"
+)
+
+type AgendaItem struct {
+	Activity *Activity
+
+	Persons    []string
+	CenterId   string
+	CenterName string
+}
+
+type AgendaDay struct {
+	Day   WeekDay
+	Items []*AgendaItem
+}
+
+type AgendaGroup struct {
+	Name string
+	Days []*AgendaDay
+}
+
+// Agenda lists the activities of a plan day by day, in start time order,
+// grouped by person, by center or by level.
+type Agenda struct {
+	GroupBy string
+	Groups  []*AgendaGroup
+}
+
+func NewAgenda(plan *Plan, groupBy string, ordering Ordering) (*Agenda, error) {
+	if groupBy == "" {
+		groupBy = AgendaByPerson
+	}
+	if groupBy != AgendaByPerson && groupBy != AgendaByCenter && groupBy != AgendaByLevel {
+		return nil, fmt.Errorf("unexpected agenda grouping %v", groupBy)
+	}
+
+	err := ordering.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	centers := slices.Clone(plan.Centers)
+	ordering.sortCenters(centers)
+	persons := plan.Persons()
+	ordering.sortPersons(persons)
+
+	groupKeys := []string{}
+	groupNames := map[string]string{}
+	groupEvents := map[string][]*Activity{}
+	items := map[*Activity]*AgendaItem{}
+	addItem := func(key string, name string, cw *CenterWeek, e *Activity) {
+		if _, ok := groupNames[key]; !ok {
+			groupKeys = append(groupKeys, key)
+			groupNames[key] = name
+		}
+
+		itemPersons := slices.Clone(e.Persons)
+		ordering.sortPersons(itemPersons)
+
+		groupEvents[key] = append(groupEvents[key], e)
+		items[e] = &AgendaItem{
+			Activity:   e,
+			Persons:    itemPersons,
+			CenterId:   cw.CenterId,
+			CenterName: cw.CenterName,
+		}
+	}
+
+	switch groupBy {
+	case AgendaByCenter:
+		for _, cw := range centers {
+			for _, e := range cw.Events {
+				addItem(cw.CenterId, cw.CenterName, cw, e)
+			}
+		}
+	case AgendaByLevel:
+		for _, cw := range centers {
+			for _, e := range cw.Events {
+				level := e.ParseName(ordering.Names).LevelName()
+				addItem(level, level, cw, e)
+			}
+		}
+	default:
+		for _, person := range persons {
+			for _, cw := range centers {
+				for _, e := range cw.Events {
+					if e.HasPerson(person) {
+						addItem(person, person, cw, e)
+					}
+				}
+			}
+		}
+	}
+
+	agenda := &Agenda{GroupBy: groupBy, Groups: []*AgendaGroup{}}
+	days := weekdays()
+	for _, key := range groupKeys {
+		dailyActivities, err := eventsByWeekday(groupEvents[key], ordering)
+		if err != nil {
+			return nil, err
+		}
+
+		group := &AgendaGroup{Name: groupNames[key], Days: []*AgendaDay{}}
+		for i, day := range days {
+			activities := dailyActivities[time.Weekday(i)]
+			if len(activities) == 0 {
+				continue
+			}
+
+			agendaDay := &AgendaDay{Day: day, Items: []*AgendaItem{}}
+			for _, a := range activities {
+				agendaDay.Items = append(agendaDay.Items, items[a])
+			}
+			group.Days = append(group.Days, agendaDay)
+		}
+
+		agenda.Groups = append(agenda.Groups, group)
+	}
+
+	return agenda, nil
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const DefaultAlignWindow = 30 * time.Minute
+
+// AlignedActivity is the activity of one person in an alignment.
+type AlignedActivity struct {
+	Person   string    `json:"person"`
+	Activity *Activity `json:"activity"`
+}
+
+// Alignment is one activity for each person at the same center on the same
+// day, starting within the window of each other.
+type Alignment struct {
+	CenterId   string             `json:"center_id"`
+	CenterName string             `json:"center_name"`
+	Day        string             `json:"day"`
+	Start      TimeOfDay          `json:"-"`
+	End        TimeOfDay          `json:"-"`
+	TimeRange  string             `json:"time_range"`
+	Activities []*AlignedActivity `json:"activities"`
+	// Wait is the time each person spends at the center outside of their
+	// activity, added up.
+	Wait time.Duration `json:"-"`
+	// WaitMinutes is Wait in minutes.
+	WaitMinutes int `json:"wait_minutes"`
+}
+
+// FindAlignments returns the alignments of the activities the persons are
+// loaded for, the least total wait first. Two or more distinct persons are
+// needed.
+func FindAlignments(plan *Plan, persons []string, window time.Duration) ([]*Alignment, error) {
+	if len(persons) < 2 {
+		return nil, fmt.Errorf("at least two persons are needed to align activities, got %v", len(persons))
+	}
+	for i, p := range persons {
+		if slices.Contains(persons[:i], p) {
+			return nil, fmt.Errorf("%v is given more than once", p)
+		}
+	}
+
+	alignments := []*Alignment{}
+	for _, cw := range plan.Centers {
+		// each person's activities at the center by day
+		byDay := map[time.Weekday][][]*Activity{}
+		for i, person := range persons {
+			for _, e := range cw.Events {
+				if !e.HasPerson(person) {
+					continue
+				}
+
+				day, err := e.Weekday()
+				if err != nil {
+					return nil, err
+				}
+				if byDay[day] == nil {
+					byDay[day] = make([][]*Activity, len(persons))
+				}
+				byDay[day][i] = append(byDay[day][i], e)
+			}
+		}
+
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			candidates := byDay[day]
+			if candidates == nil {
+				continue
+			}
+
+			err := alignCandidates(candidates, window, []*Activity{}, func(activities []*Activity) error {
+				a, err := newAlignment(cw, day, persons, activities)
+				if err != nil {
+					return err
+				}
+				alignments = append(alignments, a)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	slices.SortStableFunc(alignments, func(a, b *Alignment) int {
+		return cmp.Or(
+			cmp.Compare(a.Wait, b.Wait),
+			cmp.Compare(a.End.Minutes()-a.Start.Minutes(), b.End.Minutes()-b.Start.Minutes()),
+		)
+	})
+
+	return alignments, nil
+}
+
+// alignCandidates calls found with every choice of one activity per person
+// whose start times are all within the window.
+func alignCandidates(candidates [][]*Activity, window time.Duration, chosen []*Activity, found func([]*Activity) error) error {
+	if len(chosen) == len(candidates) {
+		return found(slices.Clone(chosen))
+	}
+
+	for _, a := range candidates[len(chosen)] {
+		st, err := a.StartTime()
+		if err != nil {
+			return err
+		}
+
+		within := true
+		for _, c := range chosen {
+			other, err := c.StartTime()
+			if err != nil {
+				return err
+			}
+			if time.Duration(st.Difference(&other))*time.Minute > window {
+				within = false
+				break
+			}
+		}
+		if !within {
+			continue
+		}
+
+		err = alignCandidates(candidates, window, append(chosen, a), found)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newAlignment(cw *CenterWeek, day time.Weekday, persons []string, activities []*Activity) (*Alignment, error) {
+	a := &Alignment{
+		CenterId:   cw.CenterId,
+		CenterName: cw.CenterName,
+		Day:        day.String(),
+		Activities: []*AlignedActivity{},
+	}
+
+	busy := 0
+	for i, activity := range activities {
+		st, err := activity.StartTime()
+		if err != nil {
+			return nil, err
+		}
+
+		et, err := activity.EndTime()
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 || st.LessThan(&a.Start) {
+			a.Start = st
+		}
+		if i == 0 || a.End.LessThan(&et) {
+			a.End = et
+		}
+		busy += et.Difference(&st)
+
+		a.Activities = append(a.Activities, &AlignedActivity{Person: persons[i], Activity: activity})
+	}
+
+	span := a.End.Difference(&a.Start)
+	a.WaitMinutes = span*len(activities) - busy
+	a.Wait = time.Duration(a.WaitMinutes) * time.Minute
+	a.TimeRange = fmt.Sprintf("%v - %v", a.Start, a.End)
+
+	return a, nil
+}
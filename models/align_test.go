@@ -0,0 +1,89 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFindAlignments(t *testing.T) {
+	plan := &Plan{Centers: []*CenterWeek{
+		{CenterId: "1", CenterName: "Pool", Events: []*Activity{
+			{Id: 1, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ana"}},
+			{Id: 2, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:30 AM - 10:00 AM", Persons: []string{"ben"}},
+			{Id: 3, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ben"}},
+			{Id: 4, Name: "Swim", DayOfWeek: "Sat", TimeRange: "11:00 AM - 11:45 AM", Persons: []string{"ben"}},
+			{Id: 5, Name: "Swim", DayOfWeek: "Sun", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ben"}},
+		}},
+		{CenterId: "2", CenterName: "Arena", Events: []*Activity{
+			{Id: 6, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ben"}},
+		}},
+	}}
+
+	cases := []struct {
+		name    string
+		persons []string
+		window  time.Duration
+		// the activity of the last person, the wait and the time range of
+		// each alignment, in order
+		activities []int
+		waits      []time.Duration
+		ranges     []string
+		err        bool
+	}{
+		{
+			name:       "same start",
+			persons:    []string{"ana", "ben"},
+			window:     10 * time.Minute,
+			activities: []int{3},
+			waits:      []time.Duration{0},
+			ranges:     []string{"9:00 AM - 9:45 AM"},
+		},
+		{
+			// ana waits 15 minutes for ben, who waited 30 minutes for the
+			// start
+			name:       "within the window",
+			persons:    []string{"ana", "ben"},
+			window:     30 * time.Minute,
+			activities: []int{3, 2},
+			waits:      []time.Duration{0, 45 * time.Minute},
+			ranges:     []string{"9:00 AM - 9:45 AM", "9:00 AM - 10:00 AM"},
+		},
+		{
+			name:    "single person",
+			persons: []string{"ana"},
+			window:  time.Hour,
+			err:     true,
+		},
+		{
+			name:    "repeated person",
+			persons: []string{"ana", "ana"},
+			window:  time.Hour,
+			err:     true,
+		},
+	}
+
+	for _, c := range cases {
+		alignments, err := FindAlignments(plan, c.persons, c.window)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		activities, waits, ranges := []int{}, []time.Duration{}, []string{}
+		for _, a := range alignments {
+			activities = append(activities, a.Activities[len(a.Activities)-1].Activity.Id)
+			waits = append(waits, a.Wait)
+			ranges = append(ranges, a.TimeRange)
+		}
+		if !slices.Equal(activities, c.activities) || !slices.Equal(waits, c.waits) || !slices.Equal(ranges, c.ranges) {
+			t.Errorf("%s: expected activities %v with waits %v at %v but got %v with %v at %v",
+				c.name, c.activities, c.waits, c.ranges, activities, waits, ranges)
+		}
+	}
+}
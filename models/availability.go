@@ -0,0 +1,192 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DriversKey is the name under which the availability of the drivers is
+// configured. Every activity needs a driver as well as the person.
+const DriversKey = "drivers"
+
+// Window is a weekly time window. No days is every day, and no From or To
+// is the start or the end of the day.
+type Window struct {
+	Days []time.Weekday
+	From *TimeOfDay
+	To   *TimeOfDay
+}
+
+// ParseWindow parses the days, full or abbreviated names, weekdays or
+// weekends, and the times of a window.
+func ParseWindow(days []string, from string, to string) (Window, error) {
+	w := Window{}
+	for _, d := range days {
+		switch strings.ToLower(strings.TrimSpace(d)) {
+		case "weekdays":
+			w.Days = append(w.Days, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case "weekend", "weekends":
+			w.Days = append(w.Days, time.Saturday, time.Sunday)
+		default:
+			day, err := ParseWeekday(d)
+			if err != nil {
+				return Window{}, err
+			}
+			w.Days = append(w.Days, day)
+		}
+	}
+
+	if from != "" {
+		t, err := ParseTimeOfDay(from)
+		if err != nil {
+			return Window{}, err
+		}
+		w.From = &t
+	}
+	if to != "" {
+		t, err := ParseTimeOfDay(to)
+		if err != nil {
+			return Window{}, err
+		}
+		w.To = &t
+	}
+	if w.From != nil && w.To != nil && !w.From.LessThan(w.To) {
+		return Window{}, fmt.Errorf("unexpected window from %v to %v", from, to)
+	}
+
+	return w, nil
+}
+
+// Contains returns whether the window contains the time on the day.
+func (w Window) Contains(day time.Weekday, start TimeOfDay, end TimeOfDay) bool {
+	if len(w.Days) > 0 && !slices.Contains(w.Days, day) {
+		return false
+	}
+	if w.From != nil && start.LessThan(w.From) {
+		return false
+	}
+	if w.To != nil && w.To.LessThan(&end) {
+		return false
+	}
+	return true
+}
+
+// Availability is when a person is available each week. An activity must fit
+// in one of its windows, and an empty availability allows every activity.
+type Availability []Window
+
+func (av Availability) contains(day time.Weekday, start TimeOfDay, end TimeOfDay) bool {
+	if len(av) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(av, func(w Window) bool { return w.Contains(day, start, end) })
+}
+
+// Allows returns whether the activity fits in the availability.
+func (av Availability) Allows(a *Activity) (bool, error) {
+	if len(av) == 0 {
+		return true, nil
+	}
+
+	day, err := a.Weekday()
+	if err != nil {
+		return false, err
+	}
+	st, err := a.StartTime()
+	if err != nil {
+		return false, err
+	}
+	et, err := a.EndTime()
+	if err != nil {
+		return false, err
+	}
+
+	return av.contains(day, st, et), nil
+}
+
+// bounds returns the days and the earliest and latest times of the windows,
+// nil when unbounded.
+func (av Availability) bounds() (days []time.Weekday, from *TimeOfDay, to *TimeOfDay) {
+	if len(av) == 0 {
+		return nil, nil, nil
+	}
+
+	allDays := false
+	allFrom, allTo := false, false
+	for _, w := range av {
+		if len(w.Days) == 0 {
+			allDays = true
+		}
+		for _, d := range w.Days {
+			if !slices.Contains(days, d) {
+				days = append(days, d)
+			}
+		}
+
+		if w.From == nil {
+			allFrom = true
+		} else if from == nil || w.From.LessThan(from) {
+			from = w.From
+		}
+		if w.To == nil {
+			allTo = true
+		} else if to == nil || to.LessThan(w.To) {
+			to = w.To
+		}
+	}
+
+	if allDays {
+		days = nil
+	}
+	if allFrom {
+		from = nil
+	}
+	if allTo {
+		to = nil
+	}
+	return days, from, to
+}
+
+// NarrowSearch restricts the days and times of the search to those that can
+// fit in every availability, so that ActiveNet returns fewer activities. The
+// activities found still need to be checked with Allows.
+func NarrowSearch(pattern *ActivitySearchPattern, availabilities ...Availability) {
+	var days []time.Weekday
+	var from, to *TimeOfDay
+	allDays := true
+	for _, av := range availabilities {
+		d, f, t := av.bounds()
+		if d != nil {
+			if allDays {
+				days = d
+				allDays = false
+			} else {
+				days = slices.DeleteFunc(days, func(day time.Weekday) bool { return !slices.Contains(d, day) })
+			}
+		}
+		if f != nil && (from == nil || from.LessThan(f)) {
+			from = f
+		}
+		if t != nil && (to == nil || t.LessThan(to)) {
+			to = t
+		}
+	}
+
+	if !allDays {
+		// one flag per day starting on Sunday, like the day checkboxes of
+		// the ActiveNet search
+		mask := []byte("0000000")
+		for _, d := range days {
+			mask[d] = '1'
+		}
+		pattern.DaysOfWeek = string(mask)
+	}
+	if from != nil {
+		pattern.TimeAfter = fmt.Sprintf("%02d:%02d", from.Hour, from.Minute)
+	}
+	if to != nil {
+		pattern.TimeBefore = fmt.Sprintf("%02d:%02d", to.Hour, to.Minute)
+	}
+}
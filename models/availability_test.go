@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAvailabilityAllows(t *testing.T) {
+	school, err := ParseWindow([]string{"weekdays"}, "15:15", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekend, err := ParseWindow([]string{"weekends"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	availability := Availability{school, weekend}
+
+	cases := []struct {
+		activity *Activity
+		expected bool
+	}{
+		{&Activity{DayOfWeek: "Mon", TimeRange: "3:30 PM - 4:15 PM"}, true},
+		{&Activity{DayOfWeek: "Tue", TimeRange: "3:00 PM - 3:45 PM"}, false},
+		{&Activity{DayOfWeek: "Sat", TimeRange: "9:00 AM - 9:45 AM"}, true},
+	}
+	for _, c := range cases {
+		ok, err := availability.Allows(c.activity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != c.expected {
+			t.Errorf("Expected %v for %v %v but got %v", c.expected, c.activity.DayOfWeek, c.activity.TimeRange, ok)
+		}
+	}
+
+	_, err = ParseWindow(nil, "16:00", "15:00")
+	if err == nil {
+		t.Errorf("Expected an error for a window ending before it starts")
+	}
+}
+
+func TestNarrowSearch(t *testing.T) {
+	person := Availability{
+		{Days: []time.Weekday{time.Monday, time.Wednesday}, From: &TimeOfDay{15, 15}},
+		{Days: []time.Weekday{time.Saturday}, From: &TimeOfDay{9, 0}, To: &TimeOfDay{12, 0}},
+	}
+	drivers := Availability{{Days: []time.Weekday{time.Wednesday, time.Saturday}}}
+
+	pattern := &ActivitySearchPattern{}
+	NarrowSearch(pattern, person, drivers)
+	if pattern.DaysOfWeek != "0001001" {
+		t.Errorf("Expected Wednesday and Saturday but got %v", pattern.DaysOfWeek)
+	}
+	if pattern.TimeAfter != "09:00" || pattern.TimeBefore != "" {
+		t.Errorf("Expected activities after 09:00 but got %q to %q", pattern.TimeAfter, pattern.TimeBefore)
+	}
+}
+
+func TestShadeUnavailable(t *testing.T) {
+	view, err := NewView(&CenterPlan{Plans: []*CenterWeek{{CenterId: "1", CenterName: "Pool"}}}, Ordering{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drivers := Availability{{Days: []time.Weekday{time.Saturday}, From: &TimeOfDay{10, 0}, To: &TimeOfDay{12, 0}}}
+	view.ShadeUnavailable(nil, drivers)
+
+	saturday := view.Centers[0].Weekdays[time.Saturday].Unavailable
+	if len(saturday) != 2 || saturday[0].StartTime != "0900" || saturday[0].Span != 4 || saturday[1].StartTime != "1200" {
+		t.Errorf("Expected Saturday to be shaded before 10:00 and from 12:00 but got %v", saturday)
+	}
+	sunday := view.Centers[0].Weekdays[time.Sunday].Unavailable
+	if len(sunday) != 1 || sunday[0].Span != len(view.Times) {
+		t.Errorf("Expected Sunday to be shaded but got %v", sunday)
+	}
+}
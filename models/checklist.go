@@ -0,0 +1,170 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+const DefaultAlternates = 3
+
+// ChecklistEntry is an activity of a registration-day checklist and the
+// center it is at.
+type ChecklistEntry struct {
+	Activity   *Activity `json:"activity"`
+	CenterId   string    `json:"center_id"`
+	CenterName string    `json:"center_name"`
+}
+
+// ChecklistSlot is an activity to register for and the alternates to try, in
+// order, when it is full.
+type ChecklistSlot struct {
+	Priority   int               `json:"priority"`
+	Entry      *ChecklistEntry   `json:"entry"`
+	Alternates []*ChecklistEntry `json:"alternates"`
+}
+
+// Checklist is the ordered list of activities a person registers for.
+type Checklist struct {
+	Person string           `json:"person"`
+	Slots  []*ChecklistSlot `json:"slots"`
+}
+
+// NewChecklist returns the checklist of the activities shortlisted or selected
+// for the person, the selected first and then in plan order. The alternates of
+// a slot are the person's other activities of the same level, parsed with the
+// rules, that don't conflict with the rest of the checklist, like
+// FindConflicts with the travel times, those on the same day and closest in
+// time first. At most maxAlternates are kept.
+func NewChecklist(plan *Plan, person string, maxAlternates int, rules NameRules, travel *TravelTimes) (*Checklist, error) {
+	primary := []*ChecklistEntry{}
+	others := []*ChecklistEntry{}
+	for _, cw := range plan.Centers {
+		for _, e := range cw.Events {
+			if !e.HasPerson(person) {
+				continue
+			}
+
+			entry := &ChecklistEntry{Activity: e, CenterId: cw.CenterId, CenterName: cw.CenterName}
+			if e.SelectionFor(person) == NotSelected {
+				others = append(others, entry)
+			} else {
+				primary = append(primary, entry)
+			}
+		}
+	}
+
+	if len(primary) == 0 {
+		return nil, fmt.Errorf("no activities are shortlisted or selected for %v", person)
+	}
+
+	slices.SortStableFunc(primary, func(a, b *ChecklistEntry) int {
+		return selectionRank(a.Activity.SelectionFor(person)) - selectionRank(b.Activity.SelectionFor(person))
+	})
+
+	checklist := &Checklist{Person: person, Slots: []*ChecklistSlot{}}
+	for i, entry := range primary {
+		slot := &ChecklistSlot{Priority: i + 1, Entry: entry, Alternates: []*ChecklistEntry{}}
+
+		level := entry.Activity.ParseName(rules).LevelName()
+		candidates := slices.Concat(primary[:i], primary[i+1:])
+		for _, other := range others {
+			if other.Activity.ParseName(rules).LevelName() != level {
+				continue
+			}
+
+			conflicts, err := conflictsWithAny(other, candidates, travel)
+			if err != nil {
+				return nil, err
+			}
+			if !conflicts {
+				slot.Alternates = append(slot.Alternates, other)
+			}
+		}
+
+		err := sortAlternates(entry.Activity, slot.Alternates)
+		if err != nil {
+			return nil, err
+		}
+		if maxAlternates >= 0 && len(slot.Alternates) > maxAlternates {
+			slot.Alternates = slot.Alternates[:maxAlternates]
+		}
+
+		checklist.Slots = append(checklist.Slots, slot)
+	}
+
+	return checklist, nil
+}
+
+func selectionRank(s Selection) int {
+	if s == Selected {
+		return 0
+	}
+	return 1
+}
+
+// conflictsWithAny reports whether the entry conflicts with any of the
+// entries on the same day.
+func conflictsWithAny(entry *ChecklistEntry, entries []*ChecklistEntry, travel *TravelTimes) (bool, error) {
+	day, err := entry.Activity.Weekday()
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range entries {
+		d, err := e.Activity.Weekday()
+		if err != nil {
+			return false, err
+		}
+		if d != day {
+			continue
+		}
+
+		centers := map[*Activity]string{entry.Activity: entry.CenterId, e.Activity: e.CenterId}
+		c, err := conflictBetween(entry.Activity, e.Activity, centers, travel)
+		if err != nil || c != nil {
+			return c != nil, err
+		}
+	}
+	return false, nil
+}
+
+// sortAlternates orders the alternates of the activity, those on the same day
+// first and then the closest in start time.
+func sortAlternates(a *Activity, alternates []*ChecklistEntry) error {
+	day, err := a.Weekday()
+	if err != nil {
+		return err
+	}
+
+	start, err := a.StartTime()
+	if err != nil {
+		return err
+	}
+
+	distance := map[*Activity]int{}
+	for _, alt := range alternates {
+		altDay, err := alt.Activity.Weekday()
+		if err != nil {
+			return err
+		}
+
+		altStart, err := alt.Activity.StartTime()
+		if err != nil {
+			return err
+		}
+
+		d := altStart.Hour*60 + altStart.Minute - start.Hour*60 - start.Minute
+		distance[alt.Activity] = max(d, -d)
+		if altDay != day {
+			// after every activity of the same day
+			distance[alt.Activity] += 24 * 60
+		}
+	}
+
+	slices.SortStableFunc(alternates, func(x, y *ChecklistEntry) int {
+		return cmp.Compare(distance[x.Activity], distance[y.Activity])
+	})
+
+	return nil
+}
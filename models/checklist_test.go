@@ -0,0 +1,92 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewChecklist(t *testing.T) {
+	plan := &Plan{Centers: []*CenterWeek{
+		{CenterId: "1", CenterName: "Pool", Events: []*Activity{
+			{Id: 1, Name: "Swim Kids 1 - Nigig | Otter", DayOfWeek: "Sat", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ana"}, Selections: map[string]Selection{"ana": Shortlisted}},
+			{Id: 2, Name: "Swim Kids 1 - Nigig | Otter", DayOfWeek: "Sun", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ana"}},
+			// conflicts with the selected skating
+			{Id: 3, Name: "Swim Kids 1 - Nigig | Otter", DayOfWeek: "Sat", TimeRange: "10:00 AM - 10:45 AM", Persons: []string{"ana"}},
+			{Id: 4, Name: "Swim Kids 1 - Nigig | Otter", DayOfWeek: "Sat", TimeRange: "11:00 AM - 11:45 AM", Persons: []string{"ana"}},
+			{Id: 5, Name: "Swim Kids 2", DayOfWeek: "Sat", TimeRange: "8:00 AM - 8:45 AM", Persons: []string{"ana"}},
+		}},
+		{CenterId: "2", CenterName: "Arena", Events: []*Activity{
+			{Id: 6, Name: "Skating 1", DayOfWeek: "Sat", TimeRange: "10:15 AM - 11:00 AM", Persons: []string{"ana"}, Selections: map[string]Selection{"ana": Selected}},
+		}},
+	}}
+
+	cases := []struct {
+		name          string
+		person        string
+		maxAlternates int
+		travel        *TravelTimes
+		// the activity of each slot and the alternates of each slot, in
+		// order
+		slots      []int
+		alternates [][]int
+		err        bool
+	}{
+		{
+			name:          "alternates",
+			person:        "ana",
+			maxAlternates: 5,
+			slots:         []int{6, 1},
+			alternates:    [][]int{{}, {4, 2}},
+		},
+		{
+			name:          "at most one alternate",
+			person:        "ana",
+			maxAlternates: 1,
+			slots:         []int{6, 1},
+			alternates:    [][]int{{}, {4}},
+		},
+		{
+			// the 11:00 swim at the pool starts when skating at the arena
+			// ends
+			name:          "travel between centers",
+			person:        "ana",
+			maxAlternates: 5,
+			travel:        &TravelTimes{Matrix: map[string]map[string]time.Duration{"1": {"2": 20 * time.Minute}}},
+			slots:         []int{6, 1},
+			alternates:    [][]int{{}, {2}},
+		},
+		{
+			name:          "nothing shortlisted",
+			person:        "ben",
+			maxAlternates: 1,
+			err:           true,
+		},
+	}
+
+	for _, c := range cases {
+		checklist, err := NewChecklist(plan, c.person, c.maxAlternates, nil, c.travel)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		slots, alternates := []int{}, [][]int{}
+		for _, slot := range checklist.Slots {
+			slots = append(slots, slot.Entry.Activity.Id)
+			ids := []int{}
+			for _, alt := range slot.Alternates {
+				ids = append(ids, alt.Activity.Id)
+			}
+			alternates = append(alternates, ids)
+		}
+		if !slices.Equal(slots, c.slots) || !slices.EqualFunc(alternates, c.alternates, slices.Equal) {
+			t.Errorf("%s: expected slots %v with alternates %v but got %v with %v", c.name, c.slots, c.alternates, slots, alternates)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Conflict is a pair of a person's activities that overlap in time, or that
+// are at different centers with less time between them than it takes to
+// travel from one to the other, when Travel is set.
+type Conflict struct {
+	Person string     `json:"person"`
+	First  *Activity  `json:"first"`
+	Second *Activity  `json:"second"`
+	Travel *TravelGap `json:"travel,omitempty"`
+}
+
+// TravelGap is the travel time needed between two activities and the time
+// there is.
+type TravelGap struct {
+	Travel time.Duration `json:"-"`
+	Gap    time.Duration `json:"-"`
+	// TravelMinutes and GapMinutes are Travel and Gap in minutes.
+	TravelMinutes int `json:"travel_minutes"`
+	GapMinutes    int `json:"gap_minutes"`
+}
+
+// FindConflicts returns every pair of conflicting activities for each person
+// in the plan. When selections are given, only the activities with one of them
+// for the person are considered. Activities at different centers conflict when the
+// travel time between them is known and longer than the gap.
+func FindConflicts(plan *Plan, selections []Selection, travel *TravelTimes) ([]*Conflict, error) {
+	conflicts := []*Conflict{}
+	for _, person := range plan.Persons() {
+		events := []*Activity{}
+		centers := map[*Activity]string{}
+		for _, cw := range plan.Centers {
+			for _, e := range cw.Events {
+				if !e.HasPerson(person) {
+					continue
+				}
+				if len(selections) > 0 && !slices.Contains(selections, e.SelectionFor(person)) {
+					continue
+				}
+				events = append(events, e)
+				centers[e] = cw.CenterId
+			}
+		}
+
+		dailyActivities, err := eventsByWeekday(events, Ordering{})
+		if err != nil {
+			return nil, err
+		}
+
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			activities := dailyActivities[d]
+			for i, a := range activities {
+				for _, b := range activities[i+1:] {
+					c, err := conflictBetween(a, b, centers, travel)
+					if err != nil {
+						return nil, err
+					}
+					if c != nil {
+						c.Person = person
+						conflicts = append(conflicts, c)
+					}
+				}
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// conflictBetween returns a conflict when the activities of the same day
+// overlap or when there isn't enough time to travel between them.
+func conflictBetween(a *Activity, b *Activity, centers map[*Activity]string, travel *TravelTimes) (*Conflict, error) {
+	overlaps, err := a.Overlaps(b)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return &Conflict{First: a, Second: b}, nil
+	}
+
+	return travelConflict(a, b, centers, travel)
+}
+
+// travelConflict returns a conflict when there isn't enough time to travel
+// between the non-overlapping activities, a starting before b.
+func travelConflict(a *Activity, b *Activity, centers map[*Activity]string, travel *TravelTimes) (*Conflict, error) {
+	d, ok := travel.Between(centers[a], centers[b])
+	if !ok || d == 0 {
+		return nil, nil
+	}
+
+	first, second := a, b
+	end, err := a.EndTime()
+	if err != nil {
+		return nil, err
+	}
+	start, err := b.StartTime()
+	if err != nil {
+		return nil, err
+	}
+	// b may end before a when they start at the same time
+	if start.LessThan(&end) {
+		first, second = b, a
+		end, err = b.EndTime()
+		if err != nil {
+			return nil, err
+		}
+		start, err = a.StartTime()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gap := time.Duration(start.Minutes()-end.Minutes()) * time.Minute
+	if gap >= d {
+		return nil, nil
+	}
+
+	return &Conflict{
+		First:  first,
+		Second: second,
+		Travel: &TravelGap{
+			Travel:        d,
+			Gap:           gap,
+			TravelMinutes: int(d / time.Minute),
+			GapMinutes:    int(gap / time.Minute),
+		},
+	}, nil
+}
@@ -0,0 +1,317 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	CostByPerson   = "person"
+	CostByCenter   = "center"
+	CostByActivity = "activity"
+)
+
+// Money is an amount in cents.
+type Money int64
+
+// Dollars returns the amount of dollars as Money, rounded to the cent.
+func Dollars(d float64) Money {
+	return Money(math.Round(d * 100))
+}
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return fmt.Sprintf("%v$%d.%02d", sign, m/100, m%100)
+}
+
+// Float returns the amount in dollars.
+func (m Money) Float() float64 {
+	return float64(m) / 100
+}
+
+// MarshalJSON writes the amount as a number of dollars.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(m.Float(), 'f', -1, 64)), nil
+}
+
+var moneyRegexp = regexp.MustCompile(`\$\s*([0-9][0-9,]*(?:\.[0-9]{1,2})?)`)
+
+// UnmarshalJSON reads a number of dollars, a label such as "$84.00" or an
+// ActiveNet fee object with such a label. A label without an amount, such as
+// "View fee details", is zero; see parseFee to tell it apart from a free
+// activity.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	money, _, err := parseMoney(data)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// parseFee reads a fee like Money.UnmarshalJSON, nil when there is none or
+// when its label has no amount.
+func parseFee(data []byte) (*Money, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	money, ok, err := parseMoney(data)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &money, nil
+}
+
+// parseMoney reads an amount of Money, false when the data is null or a label
+// without an amount.
+func parseMoney(data []byte) (Money, bool, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return 0, false, nil
+	}
+
+	var label string
+	switch data[0] {
+	case '{':
+		var fee struct {
+			Label string `json:"label"`
+		}
+		err := json.Unmarshal(data, &fee)
+		if err != nil {
+			return 0, false, err
+		}
+		label = fee.Label
+	case '"':
+		err := json.Unmarshal(data, &label)
+		if err != nil {
+			return 0, false, err
+		}
+	default:
+		var d float64
+		err := json.Unmarshal(data, &d)
+		if err != nil {
+			return 0, false, err
+		}
+		return Dollars(d), true, nil
+	}
+
+	match := moneyRegexp.FindStringSubmatch(label)
+	if match == nil {
+		return 0, false, nil
+	}
+
+	d, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return Dollars(d), true, nil
+}
+
+// Discount is a percentage taken off the fees of some persons.
+type Discount struct {
+	Name    string
+	Percent float64
+	// Persons are the persons it applies to, everybody when empty.
+	Persons []string
+	// FromPerson applies it from the nth person onwards, in the order of
+	// CostOptions.Persons and counting only the persons with activities, 2
+	// for a second child discount, to everybody when 0 or 1.
+	FromPerson int
+}
+
+func (d *Discount) appliesTo(person string, index int) bool {
+	if len(d.Persons) > 0 && !slices.Contains(d.Persons, person) {
+		return false
+	}
+	return index+1 >= d.FromPerson
+}
+
+// CostOptions are the discounts and budgets applied to a cost report.
+type CostOptions struct {
+	// Persons is the order of the persons, those of the plan it doesn't list
+	// following in alphabetical order.
+	Persons   []string
+	Discounts []Discount
+	// Budget is the family budget, none when zero, and Budgets those of
+	// each person.
+	Budget  Money
+	Budgets map[string]Money
+	// Shortlisted includes the shortlisted activities with the selected.
+	Shortlisted bool
+}
+
+// CostLine is the fee of an activity for a person. An unknown fee counts as
+// zero.
+type CostLine struct {
+	Person     string    `json:"person"`
+	CenterId   string    `json:"center_id"`
+	CenterName string    `json:"center_name"`
+	Activity   *Activity `json:"activity"`
+	Fee        Money     `json:"fee"`
+	FeeUnknown bool      `json:"fee_unknown,omitempty"`
+	Discount   Money     `json:"discount"`
+	Total      Money     `json:"total"`
+	Discounts  []string  `json:"discounts,omitempty"`
+}
+
+// CostTotal is the total of the lines of a person, a center or the family.
+type CostTotal struct {
+	Name       string `json:"name"`
+	Activities int    `json:"activities"`
+	Fee        Money  `json:"fee"`
+	Discount   Money  `json:"discount"`
+	Total      Money  `json:"total"`
+	// Unknown is the number of activities whose fee isn't known.
+	Unknown    int   `json:"unknown"`
+	Budget     Money `json:"budget,omitempty"`
+	OverBudget bool  `json:"over_budget,omitempty"`
+}
+
+func (t *CostTotal) add(l *CostLine) {
+	t.Activities++
+	t.Fee += l.Fee
+	t.Discount += l.Discount
+	t.Total += l.Total
+	if l.FeeUnknown {
+		t.Unknown++
+	}
+}
+
+func (t *CostTotal) setBudget(budget Money) {
+	t.Budget = budget
+	t.OverBudget = budget > 0 && t.Total > budget
+}
+
+// CostReport totals the fees of the selected activities per person, per
+// center and for the family.
+type CostReport struct {
+	Lines   []*CostLine  `json:"lines"`
+	Persons []*CostTotal `json:"persons"`
+	Centers []*CostTotal `json:"centers"`
+	Family  *CostTotal   `json:"family"`
+}
+
+// NewCostReport applies the discounts to the fees of the selected activities
+// of each person, in the order of the options. The percentages of the
+// discounts that apply add up, to at most 100.
+func NewCostReport(plan *Plan, options CostOptions) *CostReport {
+	report := &CostReport{
+		Lines:   []*CostLine{},
+		Persons: []*CostTotal{},
+		Centers: []*CostTotal{},
+		Family:  &CostTotal{Name: "family"},
+	}
+
+	type event struct {
+		center   *CenterWeek
+		activity *Activity
+	}
+
+	centers := map[string]*CostTotal{}
+	// counted numbers the persons with activities for Discount.FromPerson
+	counted := 0
+	for _, person := range options.order(plan.Persons()) {
+		total := &CostTotal{Name: person}
+		report.Persons = append(report.Persons, total)
+
+		events := []event{}
+		for _, cw := range plan.Centers {
+			for _, e := range cw.Events {
+				if e.HasPerson(person) && options.counts(e.SelectionFor(person)) {
+					events = append(events, event{cw, e})
+				}
+			}
+		}
+
+		percent := 0.0
+		discounts := []string{}
+		for _, d := range options.Discounts {
+			if d.appliesTo(person, counted) {
+				percent += d.Percent
+				discounts = append(discounts, d.Name)
+			}
+		}
+		percent = math.Min(percent, 100)
+		if len(events) > 0 {
+			counted++
+		}
+
+		for _, ev := range events {
+			cw, e := ev.center, ev.activity
+			line := &CostLine{
+				Person:     person,
+				CenterId:   cw.CenterId,
+				CenterName: cw.CenterName,
+				Activity:   e,
+				FeeUnknown: e.Fee == nil,
+			}
+			if e.Fee != nil {
+				line.Fee = *e.Fee
+				line.Discount = Dollars(e.Fee.Float() * percent / 100)
+			}
+			line.Total = line.Fee - line.Discount
+			if line.Discount > 0 {
+				line.Discounts = discounts
+			}
+			report.Lines = append(report.Lines, line)
+
+			center, ok := centers[cw.CenterId]
+			if !ok {
+				center = &CostTotal{Name: cw.CenterName}
+				centers[cw.CenterId] = center
+				report.Centers = append(report.Centers, center)
+			}
+
+			total.add(line)
+			center.add(line)
+			report.Family.add(line)
+		}
+
+		total.setBudget(options.Budgets[person])
+	}
+	report.Family.setBudget(options.Budget)
+
+	return report
+}
+
+// order returns the persons in the order of the options.
+func (o *CostOptions) order(persons []string) []string {
+	ordered := []string{}
+	for _, person := range o.Persons {
+		if slices.Contains(persons, person) && !slices.Contains(ordered, person) {
+			ordered = append(ordered, person)
+		}
+	}
+
+	rest := slices.DeleteFunc(slices.Clone(persons), func(person string) bool {
+		return slices.Contains(ordered, person)
+	})
+	slices.Sort(rest)
+
+	return append(ordered, rest...)
+}
+
+func (o *CostOptions) counts(s Selection) bool {
+	return s == Selected || (o.Shortlisted && s == Shortlisted)
+}
+
+// OverBudget returns the totals that exceed their budget.
+func (r *CostReport) OverBudget() []*CostTotal {
+	over := []*CostTotal{}
+	for _, t := range append(slices.Clone(r.Persons), r.Family) {
+		if t.OverBudget {
+			over = append(over, t)
+		}
+	}
+	return over
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := map[string]Money{
+		`84.5`:                          8450,
+		`"$1,084.00"`:                   108400,
+		`{"label": "$84.00"}`:           8400,
+		`{"label": "View fee details"}`: 0,
+		`null`:                          0,
+	}
+	for data, expected := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(data), &m)
+		if err != nil {
+			t.Errorf("Expected %v to parse but got %v", data, err)
+			continue
+		}
+		if m != expected {
+			t.Errorf("Expected %v for %v but got %v", expected, data, m)
+		}
+	}
+
+	fee := Money(8450)
+	data, err := json.Marshal(&Activity{Id: 1, Fee: &fee})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a Activity
+	err = json.Unmarshal(data, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Fee == nil || *a.Fee != 8450 {
+		t.Errorf("Expected the fee to round trip but got %v from %s", a.Fee, data)
+	}
+}
+
+func TestActivityFee(t *testing.T) {
+	tests := map[string]*Money{
+		`{"id": 1, "fee": {"label": "$84.00"}}`:           ptr(Money(8400)),
+		`{"id": 1, "fee": {"label": "$0.00"}}`:            ptr(Money(0)),
+		`{"id": 1, "fee": 0}`:                             ptr(Money(0)),
+		`{"id": 1, "fee": {"label": "View fee details"}}`: nil,
+		`{"id": 1, "fee": null}`:                          nil,
+		`{"id": 1}`:                                       nil,
+	}
+	for data, expected := range tests {
+		var a Activity
+		err := json.Unmarshal([]byte(data), &a)
+		if err != nil {
+			t.Errorf("Expected %v to parse but got %v", data, err)
+			continue
+		}
+		if a.Id != 1 || (a.Fee == nil) != (expected == nil) || (a.Fee != nil && *a.Fee != *expected) {
+			t.Errorf("Expected activity 1 with fee %v for %v but got %v with %v", expected, data, a.Id, a.Fee)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestNewCostReport(t *testing.T) {
+	plan := &Plan{Centers: []*CenterWeek{
+		{CenterId: "1", CenterName: "Pool", Events: []*Activity{
+			{Id: 1, Name: "Swim", Fee: ptr(Money(10000)), Persons: []string{"ana", "ben"}, Selections: map[string]Selection{"ana": Selected, "ben": Selected}},
+			{Id: 2, Name: "Swim", Fee: ptr(Money(5000)), Persons: []string{"ana"}, Selections: map[string]Selection{"ana": Shortlisted}},
+		}},
+		{CenterId: "2", CenterName: "Arena", Events: []*Activity{
+			{Id: 3, Name: "Swim", Persons: []string{"ben"}, Selections: map[string]Selection{"ben": Selected}},
+			{Id: 4, Name: "Skate", Fee: ptr(Money(0)), Persons: []string{"ana"}, Selections: map[string]Selection{"ana": Selected}},
+			{Id: 5, Name: "Skate", Fee: ptr(Money(3000)), Persons: []string{"cal"}, Selections: map[string]Selection{"cal": Shortlisted}},
+		}},
+	}}
+
+	cases := []struct {
+		name    string
+		options CostOptions
+		// the persons in order with their totals, the family total and the
+		// names of the totals over budget
+		persons []string
+		totals  []Money
+		family  Money
+		over    []string
+	}{
+		{
+			name:    "no discounts",
+			persons: []string{"ana", "ben", "cal"},
+			totals:  []Money{10000, 10000, 0},
+			family:  20000,
+			over:    []string{},
+		},
+		{
+			name: "discounts and budgets",
+			options: CostOptions{
+				Discounts: []Discount{
+					{Name: "second child", Percent: 10, FromPerson: 2},
+					{Name: "subsidy", Percent: 25, Persons: []string{"ben"}},
+				},
+				Budget:  Dollars(150),
+				Budgets: map[string]Money{"ana": Dollars(120)},
+			},
+			persons: []string{"ana", "ben", "cal"},
+			totals:  []Money{10000, 6500, 0},
+			family:  16500,
+			over:    []string{"family"},
+		},
+		{
+			name:    "shortlisted",
+			options: CostOptions{Shortlisted: true},
+			persons: []string{"ana", "ben", "cal"},
+			totals:  []Money{15000, 10000, 3000},
+			family:  28000,
+			over:    []string{},
+		},
+		{
+			name:    "discounts over 100%",
+			options: CostOptions{Discounts: []Discount{{Name: "a", Percent: 60}, {Name: "b", Percent: 60}}},
+			persons: []string{"ana", "ben", "cal"},
+			totals:  []Money{0, 0, 0},
+			family:  0,
+			over:    []string{},
+		},
+		{
+			name: "person order",
+			options: CostOptions{
+				Persons:   []string{"ben", "zed"},
+				Discounts: []Discount{{Name: "second child", Percent: 10, FromPerson: 2}},
+			},
+			persons: []string{"ben", "ana", "cal"},
+			totals:  []Money{10000, 9000, 0},
+			family:  19000,
+			over:    []string{},
+		},
+		{
+			// cal has no selected activities so ana is the first child
+			name: "person without activities",
+			options: CostOptions{
+				Persons:   []string{"cal", "ana", "ben"},
+				Discounts: []Discount{{Name: "second child", Percent: 10, FromPerson: 2}},
+			},
+			persons: []string{"cal", "ana", "ben"},
+			totals:  []Money{0, 10000, 9000},
+			family:  19000,
+			over:    []string{},
+		},
+	}
+
+	for _, c := range cases {
+		report := NewCostReport(plan, c.options)
+
+		persons, totals := []string{}, []Money{}
+		for _, p := range report.Persons {
+			persons = append(persons, p.Name)
+			totals = append(totals, p.Total)
+		}
+		over := []string{}
+		for _, o := range report.OverBudget() {
+			over = append(over, o.Name)
+		}
+		if !slices.Equal(persons, c.persons) || !slices.Equal(totals, c.totals) {
+			t.Errorf("%s: expected %v with totals %v but got %v with %v", c.name, c.persons, c.totals, persons, totals)
+		}
+		if report.Family.Total != c.family || !slices.Equal(over, c.over) {
+			t.Errorf("%s: expected %v for the family and %v over budget but got %v and %v", c.name, c.family, c.over, report.Family.Total, over)
+		}
+	}
+
+	report := NewCostReport(plan, CostOptions{})
+	if len(report.Lines) != 4 || report.Persons[0].Unknown != 0 || report.Persons[1].Unknown != 1 {
+		t.Errorf("Expected 4 lines and an unknown fee only for ben but got %v, %+v and %+v", len(report.Lines), report.Persons[0], report.Persons[1])
+	}
+	if len(report.Centers) != 2 || report.Centers[0].Total != 20000 {
+		t.Errorf("Expected $200.00 at the pool but got %+v", report.Centers)
+	}
+}
@@ -1,5 +1,13 @@
 package models
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
 type Criterium struct {
 	Id          string `json:"id"`
 	Description string `json:"desc"`
@@ -8,11 +16,204 @@ type Criterium struct {
 type Criteria = []Criterium
 
 type FiltersBody struct {
-	Centers    Criteria `json:"centers"`
-	Categories Criteria `json:"categories"`
-	Seasons    Criteria `json:"seasons"`
+	Centers         Criteria `json:"centers"`
+	Categories      Criteria `json:"categories"`
+	Seasons         Criteria `json:"seasons"`
+	ActivityTypes   Criteria `json:"activity_types"`
+	Sites           Criteria `json:"sites"`
+	AgeGroups       Criteria `json:"age_groups"`
+	Instructors     Criteria `json:"instructors"`
+	GeographicAreas Criteria `json:"geographic_areas"`
+
+	// Locations are the centers whose location gojoin has looked up, kept
+	// with the cached filters.
+	Locations []CenterLocation `json:"gojoin_center_locations,omitempty"`
+	// LocationFailures are the times the location of a center, by ID, was
+	// last looked up without success.
+	LocationFailures map[string]time.Time `json:"gojoin_location_failures,omitempty"`
+}
+
+// FilterLists are the names of the lists in FiltersBody, in display order.
+var FilterLists = []string{
+	"seasons",
+	"centers",
+	"categories",
+	"activity-types",
+	"sites",
+	"age-groups",
+	"instructors",
+	"geographic-areas",
+}
+
+// List returns the named list, one of FilterLists.
+func (f *FiltersBody) List(name string) (Criteria, error) {
+	switch name {
+	case "seasons":
+		return f.Seasons, nil
+	case "centers":
+		return f.Centers, nil
+	case "categories":
+		return f.Categories, nil
+	case "activity-types":
+		return f.ActivityTypes, nil
+	case "sites":
+		return f.Sites, nil
+	case "age-groups":
+		return f.AgeGroups, nil
+	case "instructors":
+		return f.Instructors, nil
+	case "geographic-areas":
+		return f.GeographicAreas, nil
+	}
+
+	return nil, fmt.Errorf("unknown filter list %v, expected one of %v", name, strings.Join(FilterLists, ", "))
 }
 
 type FiltersResponse struct {
 	Body FiltersBody `json:"body"`
 }
+
+// LookupError is returned when a criterium can't be resolved unambiguously.
+// Candidates are every match when the query is ambiguous, otherwise the
+// closest criteria, if any.
+type LookupError struct {
+	Kind       string
+	Query      string
+	Ambiguous  bool
+	Candidates Criteria
+}
+
+func (e *LookupError) Error() string {
+	var msg string
+	if e.Query == "" {
+		msg = fmt.Sprintf("no %v given", e.Kind)
+	} else if e.Ambiguous {
+		msg = fmt.Sprintf("%q matches more than one %v", e.Query, e.Kind)
+	} else {
+		msg = fmt.Sprintf("no %v matches %q", e.Kind, e.Query)
+	}
+
+	if len(e.Candidates) == 0 {
+		return msg
+	}
+
+	names := []string{}
+	for _, c := range e.Candidates {
+		names = append(names, fmt.Sprintf("%v (%v)", c.Description, c.Id))
+	}
+
+	return fmt.Sprintf("%v, did you mean: %v", msg, strings.Join(names, ", "))
+}
+
+const maxCandidates = 5
+
+// Find resolves a criterium by ID, by description or by fragments of the
+// description, ignoring case. A *LookupError listing close matches is
+// returned when nothing or more than one criterium matches.
+func Find(kind string, criteria Criteria, query string) (Criterium, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return Criterium{}, &LookupError{Kind: kind}
+	}
+
+	for _, c := range criteria {
+		if c.Id == query {
+			return c, nil
+		}
+	}
+
+	for _, c := range criteria {
+		if strings.EqualFold(c.Description, query) {
+			return c, nil
+		}
+	}
+
+	matches := Criteria{}
+	for _, c := range criteria {
+		if matchesFragments(c, query) {
+			matches = append(matches, c)
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	if len(matches) > 1 {
+		return Criterium{}, &LookupError{Kind: kind, Query: query, Ambiguous: true, Candidates: matches}
+	}
+
+	return Criterium{}, &LookupError{Kind: kind, Query: query, Candidates: closest(criteria, query)}
+}
+
+// matchesFragments reports whether every word of the query appears in the
+// description.
+func matchesFragments(c Criterium, query string) bool {
+	description := strings.ToLower(c.Description)
+	for _, fragment := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(description, fragment) {
+			return false
+		}
+	}
+	return true
+}
+
+// closest returns the criteria whose descriptions are nearest to the query
+// by edit distance, comparing against the start of each description.
+func closest(criteria Criteria, query string) Criteria {
+	query = strings.ToLower(query)
+	queryLength := utf8.RuneCountInString(query)
+
+	type scored struct {
+		criterium Criterium
+		distance  int
+	}
+	scores := []scored{}
+	for _, c := range criteria {
+		description := strings.ToLower(c.Description)
+		// the prefix is cut by runes so that accented names stay valid
+		prefix := description
+		if runes := []rune(prefix); len(runes) > queryLength {
+			prefix = string(runes[:queryLength])
+		}
+		distance := min(levenshtein(query, description), levenshtein(query, prefix))
+
+		// anything needing more than a third of the query changed isn't close
+		if distance*3 <= queryLength {
+			scores = append(scores, scored{c, distance})
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].distance < scores[j].distance
+	})
+
+	result := Criteria{}
+	for i := 0; i < len(scores) && i < maxCandidates; i++ {
+		result = append(result, scores[i].criterium)
+	}
+	return result
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
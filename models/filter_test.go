@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+var testCenters = Criteria{
+	{Id: "165", Description: "Plant Recreation Centre"},
+	{Id: "384", Description: "Pinecrest Recreation Complex"},
+	{Id: "12", Description: "Nepean Sportsplex"},
+}
+
+func TestFind(t *testing.T) {
+	queries := map[string]string{
+		"384":                     "384",
+		"nepean sportsplex":       "12",
+		"Plant Rec":               "165",
+		"pinecrest complex":       "384",
+		"  Sportsplex  ":          "12",
+		"Plant Recreation Centre": "165",
+	}
+
+	for query, id := range queries {
+		c, err := Find("center", testCenters, query)
+		if err != nil {
+			t.Errorf("Expected %q to find %v but got %v", query, id, err)
+		} else if c.Id != id {
+			t.Errorf("Expected %q to find %v but got %v", query, id, c.Id)
+		}
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	_, err := Find("center", testCenters, "Recreation")
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) || !lookupErr.Ambiguous || len(lookupErr.Candidates) != 2 {
+		t.Errorf("Expected an ambiguous match but got %v", err)
+	}
+
+	_, err = Find("center", testCenters, "Pinecrast")
+	if !errors.As(err, &lookupErr) || lookupErr.Ambiguous || len(lookupErr.Candidates) != 1 || lookupErr.Candidates[0].Id != "384" {
+		t.Errorf("Expected Pinecrest to be suggested but got %v", err)
+	}
+
+	_, err = Find("center", testCenters, "Orleans")
+	if !errors.As(err, &lookupErr) || len(lookupErr.Candidates) != 0 {
+		t.Errorf("Expected no suggestions but got %v", err)
+	}
+
+	_, err = Find("center", testCenters, "")
+	if err == nil || err.Error() != "no center given" {
+		t.Errorf("Expected a missing center error but got %v", err)
+	}
+}
+
+func TestFindAccented(t *testing.T) {
+	centers := Criteria{
+		{Id: "201", Description: "École des Génies et Gardiens"},
+		{Id: "202", Description: "Centre récréatif Éva-James"},
+	}
+
+	// the start of the name is compared rune by rune, not byte by byte
+	_, err := Find("center", centers, "Ecole des Geeses")
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) || len(lookupErr.Candidates) != 1 || lookupErr.Candidates[0].Id != "201" {
+		t.Errorf("Expected École des Génies et Gardiens to be suggested but got %v", err)
+	}
+
+	_, err = Find("center", centers, "Éva-Jamés récré")
+	if !errors.As(err, &lookupErr) || len(lookupErr.Candidates) != 0 {
+		t.Errorf("Expected no suggestions but got %v", err)
+	}
+}
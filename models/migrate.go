@@ -0,0 +1,180 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// SchemaVersion is the version of the plan file written by this version of
+// gojoin. Older files are upgraded on read by the Migrations.
+//
+//	1  a copy of each activity per person under "plans", with an optional
+//	   "selection" on each activity
+//	2  activities shared between persons under "centers", with "persons" and
+//	   "selections" on each activity
+const SchemaVersion = 2
+
+// Migration upgrades a decoded plan file from version From to From+1.
+type Migration struct {
+	From        int
+	Description string
+	Migrate     func(doc map[string]any) error
+}
+
+// Migrations are applied in order to upgrade older plan files. A migration
+// works on the generic JSON document rather than the Go types, so that it
+// keeps working as the types change.
+var Migrations = []Migration{
+	{From: 1, Description: "share activities between persons and move them under centers", Migrate: migrateSharedActivities},
+}
+
+// PlanVersion returns the schema version of a plan file. Files written before
+// the version was recorded are recognised by their layout.
+func PlanVersion(data []byte) (int, error) {
+	var header struct {
+		SchemaVersion *int            `json:"schema_version"`
+		Plans         json.RawMessage `json:"plans"`
+	}
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case header.SchemaVersion != nil:
+		return *header.SchemaVersion, nil
+	case header.Plans != nil:
+		return 1, nil
+	}
+
+	return 2, nil
+}
+
+// MigratePlan upgrades a plan file to SchemaVersion and returns it with the
+// version it had. Files that are already current are returned unchanged.
+func MigratePlan(data []byte) ([]byte, int, error) {
+	version, err := PlanVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version == SchemaVersion {
+		return data, version, nil
+	}
+	if version > SchemaVersion || version < 1 {
+		return nil, version, fmt.Errorf("unsupported schema version %v, this version of gojoin reads up to %v", version, SchemaVersion)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	err = decoder.Decode(&doc)
+	if err != nil {
+		return nil, version, err
+	}
+
+	for _, m := range Migrations {
+		if m.From < version {
+			continue
+		}
+
+		err = m.Migrate(doc)
+		if err != nil {
+			return nil, version, fmt.Errorf("migrating from schema version %v: %w", m.From, err)
+		}
+		doc["schema_version"] = m.From + 1
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, err
+	}
+
+	return migrated, version, nil
+}
+
+// PendingMigrations returns the migrations that upgrade a file of the version.
+func PendingMigrations(version int) []Migration {
+	pending := []Migration{}
+	for _, m := range Migrations {
+		if m.From >= version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// migrateSharedActivities converts the per person "plans" into "centers",
+// storing each activity once with the persons it belongs to and their
+// selections. The details of the last copy of an activity are kept.
+func migrateSharedActivities(doc map[string]any) error {
+	plans, _ := doc["plans"].([]any)
+	delete(doc, "plans")
+
+	centers := []any{}
+	centerIndex := map[string]map[string]any{}
+	eventIndex := map[string]map[string]any{}
+	for _, p := range plans {
+		plan, ok := p.(map[string]any)
+		if !ok {
+			return fmt.Errorf("unexpected plan %v", p)
+		}
+		person, _ := plan["person"].(string)
+
+		centerWeeks, _ := plan["center_weeks"].([]any)
+		for _, c := range centerWeeks {
+			cw, ok := c.(map[string]any)
+			if !ok {
+				return fmt.Errorf("unexpected center week %v", c)
+			}
+			centerId, _ := cw["center_id"].(string)
+
+			center, ok := centerIndex[centerId]
+			if !ok {
+				center = map[string]any{"center_id": centerId, "center_name": cw["center_name"], "events": []any{}}
+				centerIndex[centerId] = center
+				centers = append(centers, center)
+			}
+
+			events, _ := cw["events"].([]any)
+			for _, e := range events {
+				event, ok := e.(map[string]any)
+				if !ok {
+					return fmt.Errorf("unexpected event %v", e)
+				}
+				selection, _ := event["selection"].(string)
+				delete(event, "selection")
+
+				key := fmt.Sprintf("%v/%v", centerId, event["id"])
+				shared, ok := eventIndex[key]
+				if !ok {
+					shared = map[string]any{"persons": []any{}}
+					eventIndex[key] = shared
+					center["events"] = append(center["events"].([]any), shared)
+				}
+				for k, v := range event {
+					if k != "persons" && k != "selections" {
+						shared[k] = v
+					}
+				}
+
+				persons := shared["persons"].([]any)
+				if !slices.Contains(persons, any(person)) {
+					shared["persons"] = append(persons, person)
+				}
+				if selection != "" {
+					selections, _ := shared["selections"].(map[string]any)
+					if selections == nil {
+						selections = map[string]any{}
+						shared["selections"] = selections
+					}
+					selections[person] = selection
+				}
+			}
+		}
+	}
+
+	doc["centers"] = centers
+	return nil
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFixturePlan(t *testing.T, name string) (*Plan, int) {
+	data, err := os.ReadFile(filepath.Join("testdata", "plans", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := PlanVersion(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plan Plan
+	err = json.Unmarshal(data, &plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &plan, version
+}
+
+// TestMigrateFixtures loads a plan saved by each past schema version and
+// checks it matches the current one.
+func TestMigrateFixtures(t *testing.T) {
+	current, _ := readFixturePlan(t, "v2.json")
+	expected, err := json.Marshal(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withoutSelections := &Plan{Centers: []*CenterWeek{}}
+	err = json.Unmarshal(expected, withoutSelections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cw := range withoutSelections.Centers {
+		for _, e := range cw.Events {
+			e.Selections = nil
+		}
+	}
+	expectedWithoutSelections, err := json.Marshal(withoutSelections)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		version  int
+		expected []byte
+	}{
+		{"v1.json", 1, expectedWithoutSelections},
+		{"v1-selection.json", 1, expected},
+		{"v2-unversioned.json", 2, expected},
+		{"v2.json", 2, expected},
+	}
+	for _, test := range tests {
+		plan, version := readFixturePlan(t, test.name)
+		if version != test.version {
+			t.Errorf("Expected %v to have version %v but got %v", test.name, test.version, version)
+		}
+
+		actual, err := json.Marshal(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != string(test.expected) {
+			t.Errorf("Expected %v to migrate to\n%s\nbut got\n%s", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	var plan Plan
+	err := json.Unmarshal([]byte(`{"schema_version": 99, "centers": []}`), &plan)
+	if err == nil {
+		t.Errorf("Expected an error for a newer schema version")
+	}
+}
+
+func TestMarshalSchemaVersion(t *testing.T) {
+	data, err := json.Marshal(&Plan{Centers: []*CenterWeek{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := PlanVersion(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("Expected version %v but got %v", SchemaVersion, version)
+	}
+}
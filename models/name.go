@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ActivityName is the structure of an activity name such as
+// "Swim Creatures 4 - Nigig | Otter (4-6 yrs)": the program "Swim Creatures",
+// the level "4", the Indigenous name "Nigig", the English name "Otter" and
+// the ages "4-6 yrs". Parts that aren't in the name are empty.
+type ActivityName struct {
+	Program    string `json:"program"`
+	Level      string `json:"level,omitempty"`
+	Indigenous string `json:"indigenous,omitempty"`
+	English    string `json:"english,omitempty"`
+	Ages       string `json:"ages,omitempty"`
+}
+
+// LevelName returns the program and level, "Swim Creatures 4", which
+// activities that are substitutes for each other share.
+func (n ActivityName) LevelName() string {
+	return strings.TrimSpace(n.Program + " " + n.Level)
+}
+
+// NameRule is a regular expression matching activity names, with named
+// groups for the parts of ActivityName: program, level, indigenous, english
+// and ages.
+type NameRule struct {
+	*regexp.Regexp
+}
+
+// NameRules are tried in order, the first match wins. The default rules are
+// tried after them.
+type NameRules []NameRule
+
+var defaultNameRules = NameRules{
+	{regexp.MustCompile(`^(?P<program>.+?)(?:\s+(?P<level>\d+|[A-Z]))?\s+-\s+(?P<indigenous>[^|]+?)\s*\|\s*(?P<english>.+?)$`)},
+	{regexp.MustCompile(`^(?P<program>.+?)(?:\s+(?P<level>\d+|[A-Z]))?$`)},
+}
+
+var agesRegexp = regexp.MustCompile(`(?i)\s*\(?\b(\d+(?:\s*(?:-|to|–)\s*\d+)?\+?\s*(?:yrs?|years?|mos?|months?)|\d+\+)\)?`)
+
+var nameGroups = []string{"program", "level", "indigenous", "english", "ages"}
+
+// ParseNameRule compiles a name rule, which must have a program group.
+func ParseNameRule(pattern string) (NameRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return NameRule{}, err
+	}
+
+	if re.SubexpIndex("program") < 0 {
+		return NameRule{}, fmt.Errorf("name rule %v has no program group", pattern)
+	}
+	for _, name := range re.SubexpNames() {
+		if name != "" && !slices.Contains(nameGroups, name) {
+			return NameRule{}, fmt.Errorf("name rule %v has an unexpected group %v, expected one of %v", pattern, name, strings.Join(nameGroups, ", "))
+		}
+	}
+
+	return NameRule{re}, nil
+}
+
+// Parse parses the activity name with the rules and then the default rules.
+// An age hint is taken out of the name first unless a rule captures it. A
+// name that no rule matches is all program.
+func (rules NameRules) Parse(name string) ActivityName {
+	name = strings.TrimSpace(name)
+	for _, rule := range rules {
+		if n, ok := rule.parse(name); ok {
+			return n
+		}
+	}
+
+	ages := ""
+	if loc := agesRegexp.FindStringSubmatchIndex(name); loc != nil {
+		ages = name[loc[2]:loc[3]]
+		name = strings.TrimSpace(name[:loc[0]] + name[loc[1]:])
+	}
+
+	for _, rule := range defaultNameRules {
+		if n, ok := rule.parse(name); ok {
+			n.Ages = ages
+			return n
+		}
+	}
+
+	return ActivityName{Program: name, Ages: ages}
+}
+
+func (rule NameRule) parse(name string) (ActivityName, bool) {
+	match := rule.FindStringSubmatch(name)
+	if match == nil {
+		return ActivityName{}, false
+	}
+
+	group := func(g string) string {
+		i := rule.SubexpIndex(g)
+		if i < 0 {
+			return ""
+		}
+		return strings.TrimSpace(match[i])
+	}
+
+	return ActivityName{
+		Program:    group("program"),
+		Level:      group("level"),
+		Indigenous: group("indigenous"),
+		English:    group("english"),
+		Ages:       group("ages"),
+	}, true
+}
+
+// ParseName parses the name of the activity with the rules.
+func (a *Activity) ParseName(rules NameRules) ActivityName {
+	return rules.Parse(a.Name)
+}
@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestParseName(t *testing.T) {
+	tests := map[string]ActivityName{
+		"Swim Creatures 4 - Nigig | Otter":      {Program: "Swim Creatures", Level: "4", Indigenous: "Nigig", English: "Otter"},
+		"Swim Kids 10":                          {Program: "Swim Kids", Level: "10"},
+		"Preschool A - Amik | Beaver (3-5 yrs)": {Program: "Preschool", Level: "A", Indigenous: "Amik", English: "Beaver", Ages: "3-5 yrs"},
+		"Parent and Tot (6-18 mos)":             {Program: "Parent and Tot", Ages: "6-18 mos"},
+		"Aquafit":                               {Program: "Aquafit"},
+		"Adult Swim 50+":                        {Program: "Adult Swim", Ages: "50+"},
+	}
+	for name, expected := range tests {
+		n := NameRules(nil).Parse(name)
+		if n != expected {
+			t.Errorf("Expected %+v for %q but got %+v", expected, name, n)
+		}
+	}
+
+	rule, err := ParseNameRule(`^(?P<program>Learn to Skate) (?P<level>Stage \d)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NameRules{rule}.Parse("Learn to Skate Stage 2")
+	if n.LevelName() != "Learn to Skate Stage 2" || n.Level != "Stage 2" {
+		t.Errorf("Expected the configured rule to match but got %+v", n)
+	}
+
+	for _, pattern := range []string{`(?P<level>\d)`, `^(?P<program>.+) (?P<colour>\w+)$`, `(`} {
+		_, err := ParseNameRule(pattern)
+		if err == nil {
+			t.Errorf("Expected an error for %v", pattern)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+const (
+	// OrderUser is the order configured by the user, with the centers or
+	// persons it doesn't list following in the order they were added to the
+	// plan.
+	OrderUser     = "user"
+	OrderName     = "name"
+	OrderId       = "id"
+	OrderDistance = "distance"
+
+	ColourByName  = "name"
+	ColourByLevel = "level"
+)
+
+// Ordering controls the order of centers, persons and activities that start
+// at the same time. Each is one of the Order constants; OrderDistance only
+// applies to centers and uses CenterDistances, with unknown distances last,
+// and persons have no ID. OrderUser follows UserCenters, center IDs or
+// names, and UserPersons. ColourBy colours the activities of the week view by
+// name or by level, parsed from the names with Names.
+type Ordering struct {
+	Centers    string
+	Persons    string
+	Activities string
+	ColourBy   string
+
+	UserCenters     []string
+	UserPersons     []string
+	CenterDistances map[string]float64
+	Names           NameRules
+}
+
+func (o Ordering) Validate() error {
+	orders := [][2]string{{"center", o.Centers}, {"person", o.Persons}, {"activity", o.Activities}}
+	for _, ko := range orders {
+		kind, order := ko[0], ko[1]
+		switch order {
+		case "", OrderUser, OrderName:
+		case OrderId:
+			if kind == "person" {
+				return fmt.Errorf("unexpected person order %v, persons have no ID", order)
+			}
+		case OrderDistance:
+			if kind != "center" {
+				return fmt.Errorf("unexpected %v order %v, only centers can be ordered by distance", kind, order)
+			}
+		default:
+			return fmt.Errorf("unexpected %v order %v", kind, order)
+		}
+	}
+
+	switch o.ColourBy {
+	case "", ColourByName, ColourByLevel:
+	default:
+		return fmt.Errorf("unexpected colouring %v, expected %v or %v", o.ColourBy, ColourByName, ColourByLevel)
+	}
+
+	return nil
+}
+
+// colourKey returns what the activity is coloured by.
+func (o Ordering) colourKey(a *Activity) string {
+	if o.ColourBy == ColourByLevel {
+		return a.ParseName(o.Names).LevelName()
+	}
+	return a.Name
+}
+
+func (o Ordering) sortCenters(centers []*CenterWeek) {
+	switch o.Centers {
+	case "", OrderUser:
+		slices.SortStableFunc(centers, func(a, b *CenterWeek) int {
+			return cmp.Compare(userIndex(o.UserCenters, a.CenterId, a.CenterName), userIndex(o.UserCenters, b.CenterId, b.CenterName))
+		})
+	case OrderName:
+		slices.SortStableFunc(centers, func(a, b *CenterWeek) int {
+			return cmp.Or(cmp.Compare(a.CenterName, b.CenterName), cmp.Compare(a.CenterId, b.CenterId))
+		})
+	case OrderId:
+		slices.SortStableFunc(centers, func(a, b *CenterWeek) int {
+			return compareIds(a.CenterId, b.CenterId)
+		})
+	case OrderDistance:
+		slices.SortStableFunc(centers, func(a, b *CenterWeek) int {
+			da, aOk := o.CenterDistances[a.CenterId]
+			db, bOk := o.CenterDistances[b.CenterId]
+			if aOk != bOk {
+				if aOk {
+					return -1
+				}
+				return 1
+			}
+			return cmp.Or(cmp.Compare(da, db), cmp.Compare(a.CenterName, b.CenterName))
+		})
+	}
+}
+
+func (o Ordering) sortPersons(persons []string) {
+	switch o.Persons {
+	case "", OrderUser:
+		slices.SortStableFunc(persons, func(a, b string) int {
+			return cmp.Compare(userIndex(o.UserPersons, a), userIndex(o.UserPersons, b))
+		})
+	case OrderName:
+		slices.Sort(persons)
+	}
+}
+
+// userIndex returns the position in the user's order of the first of the
+// keys it lists, after every listed position when it lists none.
+func userIndex(order []string, keys ...string) int {
+	for i, item := range order {
+		if slices.Contains(keys, item) {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// sortActivities orders the activities by start time, breaking ties using
+// the activity order.
+func (o Ordering) sortActivities(activities []*Activity) {
+	switch o.Activities {
+	case OrderName:
+		slices.SortStableFunc(activities, func(a, b *Activity) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+		})
+	case OrderId:
+		slices.SortStableFunc(activities, func(a, b *Activity) int {
+			return cmp.Compare(a.Id, b.Id)
+		})
+	}
+
+	sort.Stable(ByStartTime(activities))
+}
+
+// compareIds compares numeric IDs by value and anything else as strings.
+func compareIds(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil && na != nb {
+		return cmp.Compare(na, nb)
+	}
+
+	return cmp.Compare(a, b)
+}
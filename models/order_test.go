@@ -0,0 +1,112 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortCenters(t *testing.T) {
+	cases := []struct {
+		name     string
+		ordering Ordering
+		ids      []string
+	}{
+		{name: "plan", ordering: Ordering{}, ids: []string{"30", "4", "200"}},
+		{name: "user", ordering: Ordering{Centers: OrderUser, UserCenters: []string{"200", "Arena"}}, ids: []string{"200", "4", "30"}},
+		{name: "name", ordering: Ordering{Centers: OrderName}, ids: []string{"4", "30", "200"}},
+		{name: "id", ordering: Ordering{Centers: OrderId}, ids: []string{"4", "30", "200"}},
+		{
+			name:     "distance",
+			ordering: Ordering{Centers: OrderDistance, CenterDistances: map[string]float64{"200": 1.5, "30": 3}},
+			ids:      []string{"200", "30", "4"},
+		},
+	}
+
+	for _, c := range cases {
+		centers := []*CenterWeek{
+			{CenterId: "30", CenterName: "Pool"},
+			{CenterId: "4", CenterName: "Arena"},
+			{CenterId: "200", CenterName: "Rink"},
+		}
+		c.ordering.sortCenters(centers)
+
+		ids := []string{}
+		for _, cw := range centers {
+			ids = append(ids, cw.CenterId)
+		}
+		if !slices.Equal(ids, c.ids) {
+			t.Errorf("%s: expected %v but got %v", c.name, c.ids, ids)
+		}
+	}
+}
+
+func TestSortPersons(t *testing.T) {
+	cases := []struct {
+		name     string
+		ordering Ordering
+		persons  []string
+	}{
+		{name: "plan", ordering: Ordering{}, persons: []string{"Cal", "Ann", "Bob"}},
+		{name: "user", ordering: Ordering{Persons: OrderUser, UserPersons: []string{"Bob", "Zed"}}, persons: []string{"Bob", "Cal", "Ann"}},
+		{name: "name", ordering: Ordering{Persons: OrderName}, persons: []string{"Ann", "Bob", "Cal"}},
+	}
+
+	for _, c := range cases {
+		persons := []string{"Cal", "Ann", "Bob"}
+		c.ordering.sortPersons(persons)
+		if !slices.Equal(persons, c.persons) {
+			t.Errorf("%s: expected %v but got %v", c.name, c.persons, persons)
+		}
+	}
+}
+
+func TestSortActivitiesByStartTime(t *testing.T) {
+	cases := []struct {
+		name     string
+		ordering Ordering
+		ids      []int
+	}{
+		{name: "plan", ordering: Ordering{}, ids: []int{3, 1, 2, 4}},
+		{name: "name", ordering: Ordering{Activities: OrderName}, ids: []int{2, 3, 1, 4}},
+		{name: "id", ordering: Ordering{Activities: OrderId}, ids: []int{1, 2, 3, 4}},
+	}
+
+	for _, c := range cases {
+		activities := []*Activity{
+			{Id: 4, Name: "Aquafit", TimeRange: "10:00 AM - 11:00 AM"},
+			{Id: 3, Name: "Lifesaving", TimeRange: "9:00 AM - 10:00 AM"},
+			{Id: 1, Name: "Swim Kids 1", TimeRange: "9:00 AM - 10:00 AM"},
+			{Id: 2, Name: "Bronze Star", TimeRange: "9:00 AM - 10:00 AM"},
+		}
+		c.ordering.sortActivities(activities)
+
+		ids := []int{}
+		for _, a := range activities {
+			ids = append(ids, a.Id)
+		}
+		if !slices.Equal(ids, c.ids) {
+			t.Errorf("%s: expected %v but got %v", c.name, c.ids, ids)
+		}
+	}
+}
+
+func TestOrderingValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		ordering Ordering
+		valid    bool
+	}{
+		{name: "defaults", ordering: Ordering{}, valid: true},
+		{name: "centers by distance", ordering: Ordering{Centers: OrderDistance}, valid: true},
+		{name: "persons by distance", ordering: Ordering{Persons: OrderDistance}},
+		{name: "persons by id", ordering: Ordering{Persons: OrderId}},
+		{name: "unknown", ordering: Ordering{Activities: "size"}},
+	}
+
+	for _, c := range cases {
+		err := c.ordering.Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("%s: expected valid to be %v but got %v", c.name, c.valid, err)
+		}
+	}
+}
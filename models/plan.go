@@ -1,20 +1,194 @@
 package models
 
+import (
+	"encoding/json"
+	"slices"
+)
+
 type CenterWeek struct {
 	CenterId   string      `json:"center_id"`
 	CenterName string      `json:"center_name"`
 	Events     []*Activity `json:"events"`
 }
 
-type PersonCenterWeek struct {
-	Person      string        `json:"person"`
-	CenterWeeks []*CenterWeek `json:"center_weeks"`
-}
-
+// Plan holds the activities loaded for every person, one week per center.
+// An activity is stored once and lists the persons it belongs to.
 type Plan struct {
-	Plans []*PersonCenterWeek `json:"plans"`
+	// SchemaVersion is always written as the current SchemaVersion.
+	SchemaVersion int           `json:"schema_version"`
+	Centers       []*CenterWeek `json:"centers"`
 }
 
 type CenterPlan struct {
 	Plans []*CenterWeek `json:"plans"`
 }
+
+// UnmarshalJSON upgrades plans of older schema versions before decoding them.
+func (p *Plan) UnmarshalJSON(data []byte) error {
+	data, _, err := MigratePlan(data)
+	if err != nil {
+		return err
+	}
+
+	// plan has the fields of Plan without its methods
+	type plan Plan
+	var decoded plan
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	*p = Plan(decoded)
+	if p.Centers == nil {
+		p.Centers = []*CenterWeek{}
+	}
+
+	return nil
+}
+
+func (p Plan) MarshalJSON() ([]byte, error) {
+	type plan Plan
+	p.SchemaVersion = SchemaVersion
+	return json.Marshal(plan(p))
+}
+
+// Center returns the week for the center, creating it when it doesn't exist.
+func (p *Plan) Center(centerId string, centerName string) *CenterWeek {
+	for _, cw := range p.Centers {
+		if cw.CenterId == centerId {
+			return cw
+		}
+	}
+
+	cw := &CenterWeek{CenterId: centerId, CenterName: centerName, Events: []*Activity{}}
+	p.Centers = append(p.Centers, cw)
+	return cw
+}
+
+// Persons returns every person in the plan, in the order they were added.
+func (p *Plan) Persons() []string {
+	persons := []string{}
+	for _, cw := range p.Centers {
+		for _, e := range cw.Events {
+			for _, person := range e.Persons {
+				if !slices.Contains(persons, person) {
+					persons = append(persons, person)
+				}
+			}
+		}
+	}
+
+	return persons
+}
+
+// AddActivities attaches the activities to the person at the center. An
+// activity that is already in the plan is updated and keeps the persons and
+// selections it has.
+func (p *Plan) AddActivities(person string, centerId string, centerName string, activities []*Activity) {
+	cw := p.Center(centerId, centerName)
+	for _, a := range activities {
+		a.AddPerson(person)
+	}
+	cw.Events = mergeActivities(append(cw.Events, activities...))
+}
+
+// SetActivities replaces the person's activities at the center. Activities no
+// longer attached to anybody are removed from the plan.
+func (p *Plan) SetActivities(person string, centerId string, centerName string, activities []*Activity) {
+	cw := p.Center(centerId, centerName)
+
+	selections := map[int]Selection{}
+	events := []*Activity{}
+	for _, e := range cw.Events {
+		if e.HasPerson(person) {
+			selections[e.Id] = e.SelectionFor(person)
+			e.RemovePerson(person)
+		}
+		if len(e.Persons) > 0 {
+			events = append(events, e)
+		}
+	}
+
+	for _, a := range activities {
+		a.SetSelection(person, selections[a.Id])
+		a.AddPerson(person)
+	}
+
+	cw.Events = mergeActivities(append(events, activities...))
+}
+
+// mergeActivities removes activities with a duplicate ID. The persons and
+// selections of the duplicates are combined, while the details of the last
+// one are kept.
+func mergeActivities(activities []*Activity) []*Activity {
+	merged := []*Activity{}
+	index := map[int]int{}
+	for _, a := range activities {
+		i, ok := index[a.Id]
+		if !ok {
+			index[a.Id] = len(merged)
+			merged = append(merged, a)
+			continue
+		}
+
+		previous := merged[i]
+		for _, person := range previous.Persons {
+			if !a.HasPerson(person) {
+				a.AddPerson(person)
+				a.SetSelection(person, previous.SelectionFor(person))
+			}
+		}
+		merged[i] = a
+	}
+
+	return merged
+}
+
+// CenterPlan returns the week of each center in the plan.
+func (p *Plan) CenterPlan() *CenterPlan {
+	centerPlan := &CenterPlan{Plans: []*CenterWeek{}}
+	for _, cw := range p.Centers {
+		centerPlan.Plans = append(centerPlan.Plans, &CenterWeek{
+			CenterId:   cw.CenterId,
+			CenterName: cw.CenterName,
+			Events:     cw.Events,
+		})
+	}
+
+	return centerPlan
+}
+
+// ForPerson returns a plan containing only the given person's activities.
+func (p *Plan) ForPerson(person string) *Plan {
+	result := &Plan{Centers: []*CenterWeek{}}
+	for _, cw := range p.Centers {
+		events := []*Activity{}
+		for _, e := range cw.Events {
+			if e.HasPerson(person) {
+				events = append(events, e)
+			}
+		}
+
+		if len(events) > 0 {
+			result.Centers = append(result.Centers, &CenterWeek{
+				CenterId:   cw.CenterId,
+				CenterName: cw.CenterName,
+				Events:     events,
+			})
+		}
+	}
+
+	return result
+}
+
+// ForCenter returns a plan containing only the given center's week.
+func (cp *CenterPlan) ForCenter(centerId string) *CenterPlan {
+	result := &CenterPlan{Plans: []*CenterWeek{}}
+	for _, cw := range cp.Plans {
+		if cw.CenterId == centerId {
+			result.Plans = append(result.Plans, cw)
+		}
+	}
+
+	return result
+}
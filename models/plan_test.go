@@ -0,0 +1,114 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUnmarshalLegacyPlan(t *testing.T) {
+	data := `{"plans":[
+		{"person":"Bob","center_weeks":[{"center_id":"165","center_name":"Plant","events":[
+			{"id":1,"name":"Swim Kids 2","time_range":"9:45 AM - 10:15 AM","days_of_week":"Sun","selection":"selected"},
+			{"id":2,"name":"Swim Kids 3","time_range":"10:00 AM - 10:30 AM","days_of_week":"Sun"}]}]},
+		{"person":"Ann","center_weeks":[{"center_id":"165","center_name":"Plant","events":[
+			{"id":1,"name":"Swim Kids 2","time_range":"9:45 AM - 10:15 AM","days_of_week":"Sun"}]}]}]}`
+
+	var plan Plan
+	err := json.Unmarshal([]byte(data), &plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Centers) != 1 || len(plan.Centers[0].Events) != 2 {
+		t.Fatalf("Expected one center with 2 activities but got %v", plan.Centers)
+	}
+
+	shared := plan.Centers[0].Events[0]
+	if shared.Id != 1 || len(shared.Persons) != 2 {
+		t.Errorf("Expected activity 1 to be shared by 2 persons but got %v", shared.Persons)
+	}
+	if shared.SelectionFor("Bob") != Selected || shared.SelectionFor("Ann") != NotSelected {
+		t.Errorf("Expected activity 1 to be selected for Bob only but got %v", shared.Selections)
+	}
+}
+
+func TestSetActivities(t *testing.T) {
+	plan := &Plan{}
+	plan.AddActivities("Bob", "165", "Plant", []*Activity{{Id: 1}, {Id: 2}})
+	plan.AddActivities("Ann", "165", "Plant", []*Activity{{Id: 2}})
+	plan.Centers[0].Events[1].SetSelection("Bob", Shortlisted)
+
+	plan.SetActivities("Bob", "165", "Plant", []*Activity{{Id: 2, Name: "Updated"}, {Id: 3}})
+
+	events := plan.Centers[0].Events
+	if len(events) != 2 || events[0].Id != 2 || events[1].Id != 3 {
+		t.Fatalf("Expected activities 2 and 3 but got %v", events)
+	}
+	if events[0].Name != "Updated" || len(events[0].Persons) != 2 {
+		t.Errorf("Expected activity 2 to be updated and shared but got %v", events[0])
+	}
+	if events[0].SelectionFor("Bob") != Shortlisted {
+		t.Errorf("Expected Bob's selection to be kept")
+	}
+}
+
+func TestNewCenterViewMergesDuplicates(t *testing.T) {
+	cw := &CenterWeek{CenterId: "165", Events: []*Activity{
+		{Id: 1, TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "Sun", Persons: []string{"Bob"}},
+		{Id: 1, TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "Sun", Persons: []string{"Ann"}},
+	}}
+
+	cv, err := NewCenterView(cw, weekdays(), Ordering{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sunday := cv.Weekdays[0]
+	if len(sunday.Events) != 1 || sunday.Span != 1 {
+		t.Fatalf("Expected a single card but got %v", sunday.Events)
+	}
+	if len(sunday.Events[0].Persons) != 2 {
+		t.Errorf("Expected the card to list both persons but got %v", sunday.Events[0].Persons)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	plan := &Plan{Centers: []*CenterWeek{
+		{CenterId: "165", Events: []*Activity{
+			{Id: 1, TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "sunday", Persons: []string{"Bob"}},
+			{Id: 2, TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "Sun"},
+			{Id: 1, TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "Sun", Persons: []string{"Ann"},
+				Selections: map[string]Selection{"Ann": "maybe", "Zed": Selected}},
+		}},
+		{CenterId: "200"},
+	}}
+
+	problems := plan.Problems()
+	if len(problems) != 6 {
+		t.Errorf("Expected 6 problems but got %v", problems)
+	}
+
+	fixed := plan.Repair()
+	if len(fixed) != 5 {
+		t.Errorf("Expected 5 fixed problems but got %v", fixed)
+	}
+
+	remaining := plan.Problems()
+	if len(remaining) != 1 || remaining[0].Fixable {
+		t.Errorf("Expected the activity without persons to remain but got %v", remaining)
+	}
+
+	if len(plan.Centers) != 1 || len(plan.Centers[0].Events) != 2 {
+		t.Fatalf("Expected a single center with 2 activities but got %v", plan.Centers)
+	}
+
+	a := plan.Centers[0].Events[0]
+	if a.DayOfWeek != "Sun" || len(a.Persons) != 2 || len(a.Selections) != 0 {
+		t.Errorf("Expected the merged activity on Sun for 2 persons without selections but got %v", a)
+	}
+
+	unattached := plan.Centers[0].Events[1]
+	if unattached.Id != 2 {
+		t.Errorf("Expected activity 2 to survive the repair but got %v", unattached)
+	}
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SortDay    = "day"
+	SortTime   = "time"
+	SortName   = "name"
+	SortNumber = "number"
+	SortId     = "id"
+)
+
+var SortKeys = []string{SortDay, SortTime, SortName, SortNumber, SortId}
+
+// ActivityFilter selects activities by day, start time and name. Unset fields
+// match every activity.
+type ActivityFilter struct {
+	Days []time.Weekday
+	// StartAfter is inclusive and StartBefore exclusive.
+	StartAfter  *TimeOfDay
+	StartBefore *TimeOfDay
+	Name        *regexp.Regexp
+	// Levels are level numbers, "4", or programs and levels, "Swim Kids 4",
+	// matched against the names parsed with Names.
+	Levels []string
+	Names  NameRules
+	// Availability drops activities that don't fit in each availability.
+	Availability []Availability
+}
+
+func (f *ActivityFilter) Match(a *Activity) (bool, error) {
+	if len(f.Days) > 0 {
+		day, err := a.Weekday()
+		if err != nil {
+			return false, err
+		}
+		if !slices.Contains(f.Days, day) {
+			return false, nil
+		}
+	}
+
+	if f.StartAfter != nil || f.StartBefore != nil {
+		st, err := a.StartTime()
+		if err != nil {
+			return false, err
+		}
+		if f.StartAfter != nil && st.LessThan(f.StartAfter) {
+			return false, nil
+		}
+		if f.StartBefore != nil && !st.LessThan(f.StartBefore) {
+			return false, nil
+		}
+	}
+
+	if f.Name != nil && !f.Name.MatchString(a.Name) {
+		return false, nil
+	}
+
+	if len(f.Levels) > 0 {
+		name := a.ParseName(f.Names)
+		match := slices.ContainsFunc(f.Levels, func(level string) bool {
+			return name.Level != "" && strings.EqualFold(level, name.Level) || strings.EqualFold(level, name.LevelName())
+		})
+		if !match {
+			return false, nil
+		}
+	}
+
+	for _, av := range f.Availability {
+		ok, err := av.Allows(a)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Apply returns the activities matching the filter.
+func (f *ActivityFilter) Apply(activities []*Activity) ([]*Activity, error) {
+	result := []*Activity{}
+	for _, a := range activities {
+		ok, err := f.Match(a)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, a)
+		}
+	}
+
+	return result, nil
+}
+
+// SortActivities sorts the activities by one of the Sort keys. Day and time
+// break ties with each other, and every key finally with the ID.
+func SortActivities(activities []*Activity, key string) error {
+	if key == SortDay || key == SortTime {
+		for _, a := range activities {
+			_, err := a.Weekday()
+			if err != nil {
+				return err
+			}
+			_, err = a.StartTime()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	byDay := func(a, b *Activity) int {
+		da, _ := a.Weekday()
+		db, _ := b.Weekday()
+		return cmp.Compare(da, db)
+	}
+	byTime := func(a, b *Activity) int {
+		sa, _ := a.StartTime()
+		sb, _ := b.StartTime()
+		return cmp.Compare(sa.Hour*60+sa.Minute, sb.Hour*60+sb.Minute)
+	}
+	byId := func(a, b *Activity) int {
+		return cmp.Compare(a.Id, b.Id)
+	}
+
+	var compare func(a, b *Activity) int
+	switch key {
+	case SortDay:
+		compare = func(a, b *Activity) int {
+			return cmp.Or(byDay(a, b), byTime(a, b), byId(a, b))
+		}
+	case SortTime:
+		compare = func(a, b *Activity) int {
+			return cmp.Or(byTime(a, b), byDay(a, b), byId(a, b))
+		}
+	case SortName:
+		compare = func(a, b *Activity) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), byId(a, b))
+		}
+	case SortNumber:
+		compare = func(a, b *Activity) int {
+			return cmp.Or(compareIds(a.Number, b.Number), byId(a, b))
+		}
+	case SortId:
+		compare = byId
+	default:
+		return fmt.Errorf("unexpected sort key %v, expected one of %v", key, SortKeys)
+	}
+
+	slices.SortStableFunc(activities, compare)
+	return nil
+}
+
+// ParseWeekday parses a day name, either in full or abbreviated like the days
+// of an activity, ignoring case.
+func ParseWeekday(s string) (time.Weekday, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if lower == name || lower == name[:3] {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unexpected day %v", s)
+}
+
+// ParseTimeOfDay parses a time in 24 hour format, "15:30", or with AM or PM,
+// "3:30 PM".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	re := regexp.MustCompile(`^(\d{1,2}):(\d{2})\s*(?i:(AM|PM))?$`)
+	matches := re.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return TimeOfDay{}, fmt.Errorf("unexpected time %v, expected 15:30 or 3:30 PM", s)
+	}
+
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	switch strings.ToUpper(matches[3]) {
+	case "AM":
+		if hour < 1 || hour > 12 {
+			return TimeOfDay{}, fmt.Errorf("unexpected hour in %v", s)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "PM":
+		if hour < 1 || hour > 12 {
+			return TimeOfDay{}, fmt.Errorf("unexpected hour in %v", s)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return TimeOfDay{}, fmt.Errorf("unexpected time %v", s)
+	}
+
+	return TimeOfDay{hour, minute}, nil
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := map[string]TimeOfDay{
+		"15:30":    {15, 30},
+		"3:30 PM":  {15, 30},
+		"3:30pm":   {15, 30},
+		"12:00 PM": {12, 0},
+		"12:15 AM": {0, 15},
+		"09:05":    {9, 5},
+	}
+	for s, expected := range tests {
+		tod, err := ParseTimeOfDay(s)
+		if err != nil {
+			t.Errorf("Expected %v to parse but got %v", s, err)
+			continue
+		}
+		if tod != expected {
+			t.Errorf("Expected %v for %v but got %v", expected, s, tod)
+		}
+	}
+
+	for _, s := range []string{"", "25:00", "13:00 PM", "3 PM", "10:75"} {
+		_, err := ParseTimeOfDay(s)
+		if err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestActivityFilter(t *testing.T) {
+	activities := []*Activity{
+		{Id: 1, Name: "Swim Kids 1", TimeRange: "9:00 AM - 9:45 AM", DayOfWeek: "Sat"},
+		{Id: 2, Name: "Swim Kids 2", TimeRange: "3:30 PM - 4:15 PM", DayOfWeek: "Sat"},
+		{Id: 3, Name: "Pottery", TimeRange: "4:00 PM - 5:00 PM", DayOfWeek: "Mon"},
+	}
+	after := TimeOfDay{15, 30}
+	before := TimeOfDay{16, 0}
+
+	filter := ActivityFilter{
+		Days:        []time.Weekday{time.Saturday},
+		StartAfter:  &after,
+		StartBefore: &before,
+		Name:        regexp.MustCompile("(?i)swim"),
+	}
+	result, err := filter.Apply(activities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].Id != 2 {
+		t.Errorf("Expected only activity 2 but got %v", result)
+	}
+
+	for _, level := range []string{"1", "swim kids 1"} {
+		filter = ActivityFilter{Levels: []string{level}}
+		result, err = filter.Apply(activities)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 1 || result[0].Id != 1 {
+			t.Errorf("Expected only activity 1 at level %v but got %v", level, result)
+		}
+	}
+}
+
+func TestSortActivities(t *testing.T) {
+	activities := []*Activity{
+		{Id: 3, Name: "B", Number: "10", TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "Sat"},
+		{Id: 1, Name: "C", Number: "9", TimeRange: "4:00 PM - 5:00 PM", DayOfWeek: "Mon"},
+		{Id: 2, Name: "A", Number: "11", TimeRange: "9:00 AM - 10:00 AM", DayOfWeek: "Mon"},
+	}
+
+	tests := map[string][]int{
+		SortDay:    {2, 1, 3},
+		SortTime:   {2, 3, 1},
+		SortName:   {2, 3, 1},
+		SortNumber: {1, 3, 2},
+		SortId:     {1, 2, 3},
+	}
+	for key, expected := range tests {
+		err := SortActivities(activities, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, a := range activities {
+			if a.Id != expected[i] {
+				t.Errorf("Expected %v sorted by %v but got ID %v at %v", expected, key, a.Id, i)
+				break
+			}
+		}
+	}
+
+	err := SortActivities(activities, "colour")
+	if err == nil {
+		t.Errorf("Expected an error for an unknown sort key")
+	}
+}
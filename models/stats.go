@@ -0,0 +1,236 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	FillByActivity = "activity"
+	FillByLevel    = "level"
+	FillByCenter   = "center"
+)
+
+// OpeningsSnapshot is the openings of an activity at a point in time, as
+// recorded in the openings history.
+type OpeningsSnapshot struct {
+	Time       time.Time `json:"time"`
+	SeasonId   string    `json:"season_id,omitempty"`
+	CenterId   string    `json:"center_id,omitempty"`
+	CenterName string    `json:"center_name,omitempty"`
+	Id         int       `json:"id"`
+	Name       string    `json:"name"`
+	Number     string    `json:"number,omitempty"`
+	Openings   string    `json:"openings"`
+	Count      int       `json:"count"`
+	Waitlist   int       `json:"wait_list_count,omitempty"`
+}
+
+// ActivityFill is how an activity filled up: when it was first seen with
+// openings and when it was first seen full. SoldOut is nil when it never
+// was.
+type ActivityFill struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Number     string     `json:"number,omitempty"`
+	Level      string     `json:"level"`
+	CenterId   string     `json:"center_id,omitempty"`
+	CenterName string     `json:"center_name,omitempty"`
+	FirstSeen  time.Time  `json:"first_seen"`
+	Initial    int        `json:"initial_openings"`
+	SoldOut    *time.Time `json:"sold_out,omitempty"`
+}
+
+// TimeToFill returns how long the activity took to sell out after it was
+// first seen, false when it didn't.
+func (f *ActivityFill) TimeToFill() (time.Duration, bool) {
+	if f.SoldOut == nil {
+		return 0, false
+	}
+	return f.SoldOut.Sub(f.FirstSeen), true
+}
+
+// FillGroup summarises the fill of the activities of a level or a center.
+type FillGroup struct {
+	Name       string        `json:"name"`
+	Activities int           `json:"activities"`
+	SoldOut    int           `json:"sold_out"`
+	Median     time.Duration `json:"median_time_to_fill"`
+	Fastest    time.Duration `json:"fastest_time_to_fill"`
+}
+
+// FillStats computes how each activity of the snapshots filled up, optionally
+// only for one season, the fastest to sell out first. Activities never seen
+// with openings are left out, since when they opened is unknown. Their levels
+// are parsed from their names with the rules.
+func FillStats(snapshots []*OpeningsSnapshot, seasonId string, rules NameRules) []*ActivityFill {
+	sorted := slices.Clone(snapshots)
+	slices.SortStableFunc(sorted, func(a, b *OpeningsSnapshot) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	fills := map[int]*ActivityFill{}
+	for _, s := range sorted {
+		if seasonId != "" && s.SeasonId != seasonId {
+			continue
+		}
+
+		f, ok := fills[s.Id]
+		if !ok {
+			if s.Count == 0 {
+				continue
+			}
+			f = &ActivityFill{
+				Id:        s.Id,
+				Level:     rules.Parse(s.Name).LevelName(),
+				FirstSeen: s.Time,
+				Initial:   s.Count,
+			}
+			fills[s.Id] = f
+		}
+
+		f.Name = s.Name
+		f.Number = s.Number
+		if s.CenterId != "" || s.CenterName != "" {
+			f.CenterId = s.CenterId
+			f.CenterName = s.CenterName
+		}
+		if s.Count == 0 && f.SoldOut == nil {
+			f.SoldOut = &s.Time
+		}
+	}
+
+	result := []*ActivityFill{}
+	for _, f := range fills {
+		result = append(result, f)
+	}
+	slices.SortFunc(result, compareFill)
+
+	return result
+}
+
+// compareFill orders the fastest to sell out first and those that didn't
+// last.
+func compareFill(a, b *ActivityFill) int {
+	da, aOk := a.TimeToFill()
+	db, bOk := b.TimeToFill()
+	if aOk != bOk {
+		if aOk {
+			return -1
+		}
+		return 1
+	}
+	return cmp.Or(cmp.Compare(da, db), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+}
+
+// GroupFill summarises the fills by activity, level or center, the fastest
+// median first.
+func GroupFill(fills []*ActivityFill, by string) ([]*FillGroup, error) {
+	var key func(f *ActivityFill) string
+	switch by {
+	case FillByActivity:
+		key = func(f *ActivityFill) string {
+			return fmt.Sprintf("%v (%v)", f.Name, cmp.Or(f.Number, strconv.Itoa(f.Id)))
+		}
+	case FillByLevel:
+		key = func(f *ActivityFill) string { return f.Level }
+	case FillByCenter:
+		key = func(f *ActivityFill) string { return cmp.Or(f.CenterName, f.CenterId) }
+	default:
+		return nil, fmt.Errorf("unexpected grouping %v, expected %v, %v or %v", by, FillByActivity, FillByLevel, FillByCenter)
+	}
+
+	names := []string{}
+	durations := map[string][]time.Duration{}
+	counts := map[string]int{}
+	for _, f := range fills {
+		k := key(f)
+		if counts[k] == 0 {
+			names = append(names, k)
+		}
+		counts[k]++
+		if d, ok := f.TimeToFill(); ok {
+			durations[k] = append(durations[k], d)
+		}
+	}
+
+	groups := []*FillGroup{}
+	for _, name := range names {
+		g := &FillGroup{Name: name, Activities: counts[name], SoldOut: len(durations[name])}
+		if ds := durations[name]; len(ds) > 0 {
+			slices.Sort(ds)
+			g.Fastest = ds[0]
+			g.Median = ds[len(ds)/2]
+			if len(ds)%2 == 0 {
+				g.Median = (ds[len(ds)/2-1] + ds[len(ds)/2]) / 2
+			}
+		}
+		groups = append(groups, g)
+	}
+
+	slices.SortStableFunc(groups, func(a, b *FillGroup) int {
+		if (a.SoldOut == 0) != (b.SoldOut == 0) {
+			if a.SoldOut > 0 {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.Median, b.Median), cmp.Compare(a.Name, b.Name))
+	})
+
+	return groups, nil
+}
+
+// FormatDuration formats a duration for people: "45s", "12m", "3h 5m" or
+// "2d 4h".
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return strconv.Itoa(int(d.Seconds())) + "s"
+	case d < time.Hour:
+		return strconv.Itoa(int(d.Minutes())) + "m"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+// FillBar is a bar of the time to fill chart of the HTML view.
+type FillBar struct {
+	Name    string
+	Label   string
+	Percent int
+}
+
+// FillChart returns a bar for each group that sold out, the median time to
+// fill relative to the slowest.
+func FillChart(groups []*FillGroup) []*FillBar {
+	var slowest time.Duration
+	for _, g := range groups {
+		if g.SoldOut > 0 && g.Median > slowest {
+			slowest = g.Median
+		}
+	}
+
+	bars := []*FillBar{}
+	for _, g := range groups {
+		if g.SoldOut == 0 {
+			continue
+		}
+
+		percent := 100
+		if slowest > 0 {
+			percent = max(1, int(100*g.Median/slowest))
+		}
+		bars = append(bars, &FillBar{
+			Name:    g.Name,
+			Label:   fmt.Sprintf("%v (%v of %v sold out)", FormatDuration(g.Median), g.SoldOut, g.Activities),
+			Percent: percent,
+		})
+	}
+
+	return bars
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFillStats(t *testing.T) {
+	start := time.Date(2024, 12, 10, 19, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	snapshots := []*OpeningsSnapshot{
+		{Time: at(0), SeasonId: "1", CenterName: "Pool", Id: 1, Name: "Swim Kids 1 - Nigig | Otter", Count: 8},
+		{Time: at(0), SeasonId: "1", CenterName: "Pool", Id: 2, Name: "Swim Kids 1 - Nigig | Otter", Count: 6},
+		{Time: at(0), SeasonId: "1", CenterName: "Pool", Id: 3, Name: "Swim Kids 2", Count: 0},
+		{Time: at(0), SeasonId: "2", CenterName: "Pool", Id: 4, Name: "Swim Kids 2", Count: 3},
+		{Time: at(10), SeasonId: "1", CenterName: "Pool", Id: 2, Name: "Swim Kids 1 - Nigig | Otter", Count: 0},
+		{Time: at(30), SeasonId: "1", CenterName: "Pool", Id: 1, Name: "Swim Kids 1 - Nigig | Otter", Count: 0},
+		{Time: at(20), SeasonId: "1", CenterName: "Pool", Id: 1, Name: "Swim Kids 1 - Nigig | Otter", Count: 2},
+		{Time: at(40), SeasonId: "1", CenterName: "Pool", Id: 2, Name: "Swim Kids 1 - Nigig | Otter", Count: 1},
+	}
+
+	fills := FillStats(snapshots, "1", nil)
+	if len(fills) != 2 {
+		t.Fatalf("Expected 2 fills but got %v", len(fills))
+	}
+	if fills[0].Id != 2 || fills[1].Id != 1 {
+		t.Errorf("Expected the fastest to fill first but got %v and %v", fills[0].Id, fills[1].Id)
+	}
+	if d, ok := fills[0].TimeToFill(); !ok || d != 10*time.Minute {
+		t.Errorf("Expected 10m to fill but got %v", d)
+	}
+	if d, ok := fills[1].TimeToFill(); !ok || d != 30*time.Minute {
+		t.Errorf("Expected 30m to fill but got %v", d)
+	}
+	if fills[1].Initial != 8 {
+		t.Errorf("Expected 8 initial openings but got %v", fills[1].Initial)
+	}
+
+	groups, err := GroupFill(fills, FillByLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Name != "Swim Kids 1" {
+		t.Fatalf("Expected a Swim Kids 1 group but got %v", groups)
+	}
+	if groups[0].SoldOut != 2 || groups[0].Median != 20*time.Minute || groups[0].Fastest != 10*time.Minute {
+		t.Errorf("Expected 2 sold out, a 20m median and 10m fastest but got %+v", groups[0])
+	}
+
+	bars := FillChart(groups)
+	if len(bars) != 1 || bars[0].Percent != 100 {
+		t.Errorf("Expected a full bar but got %v", bars)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		45 * time.Second:             "45s",
+		12 * time.Minute:             "12m",
+		3*time.Hour + 5*time.Minute:  "3h 5m",
+		2*24*time.Hour + 4*time.Hour: "2d 4h",
+	}
+	for d, expected := range tests {
+		if s := FormatDuration(d); s != expected {
+			t.Errorf("Expected %v for %v but got %v", expected, d, s)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultTravelSpeed = 30.0
+
+// CenterLocation is where a center is.
+type CenterLocation struct {
+	CenterId   string  `json:"center_id"`
+	CenterName string  `json:"center_name,omitempty"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+}
+
+// MapSearchResponse is the response to an activity search with for_map set,
+// which gives the location of each activity.
+type MapSearchResponse struct {
+	Body *struct {
+		ActivityItems []*struct {
+			Location *struct {
+				Label     string  `json:"label"`
+				Latitude  float64 `json:"latitude"`
+				Longitude float64 `json:"longitude"`
+			} `json:"location"`
+		} `json:"activity_items"`
+	} `json:"body"`
+}
+
+// TravelTimes estimates the time it takes to get from one center to another,
+// from Matrix when it has the pair and otherwise from the straight-line
+// distance between their Locations at Speed km/h.
+type TravelTimes struct {
+	Locations map[string]CenterLocation
+	// Speed in km/h, DefaultTravelSpeed when zero.
+	Speed float64
+	// Matrix holds travel times by center ID, in either direction.
+	Matrix map[string]map[string]time.Duration
+}
+
+// Between returns the travel time between the centers, false when it is
+// unknown. It is zero within a center.
+func (t *TravelTimes) Between(from string, to string) (time.Duration, bool) {
+	if from == to {
+		return 0, true
+	}
+	if t == nil {
+		return 0, false
+	}
+
+	if d, ok := t.Matrix[from][to]; ok {
+		return d, true
+	}
+	if d, ok := t.Matrix[to][from]; ok {
+		return d, true
+	}
+
+	a, aOk := t.Locations[from]
+	b, bOk := t.Locations[to]
+	if !aOk || !bOk {
+		return 0, false
+	}
+
+	speed := t.Speed
+	if speed <= 0 {
+		speed = DefaultTravelSpeed
+	}
+	hours := Distance(a, b) / speed
+	return time.Duration(math.Ceil(hours*60)) * time.Minute, true
+}
+
+// Distance returns the great-circle distance between the locations in km.
+func Distance(a CenterLocation, b CenterLocation) float64 {
+	const earthRadius = 6371.0
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// ParseDistance parses a distance such as "5km", "800m" or "3mi" into km. A
+// number without a unit is in km.
+func ParseDistance(s string) (float64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		km     float64
+	}{{"km", 1}, {"mi", 1.609344}, {"m", 0.001}}
+
+	factor := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(value, u.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			factor = u.km
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("unexpected distance %q, expected e.g. 5km, 800m or 3mi", s)
+	}
+	return n * factor, nil
+}
+
+// CenterDistances returns the distance in km from home to each of the
+// locations, by center ID.
+func CenterDistances(locations map[string]CenterLocation, home CenterLocation) map[string]float64 {
+	distances := map[string]float64{}
+	for id, l := range locations {
+		distances[id] = Distance(home, l)
+	}
+	return distances
+}
+
+// CentersWithin returns the centers at most radius km away, by distance from
+// nearest to furthest. Centers whose distance is unknown are left out.
+func CentersWithin(centers Criteria, distances map[string]float64, radius float64) Criteria {
+	result := Criteria{}
+	for _, c := range centers {
+		if d, ok := distances[c.Id]; ok && d <= radius {
+			result = append(result, c)
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b Criterium) int {
+		return cmp.Or(cmp.Compare(distances[a.Id], distances[b.Id]), cmp.Compare(a.Description, b.Description))
+	})
+	return result
+}
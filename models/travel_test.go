@@ -0,0 +1,99 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTravelTimesBetween(t *testing.T) {
+	travel := &TravelTimes{
+		Locations: map[string]CenterLocation{
+			// about 11 km apart
+			"1": {CenterId: "1", Latitude: 45.4215, Longitude: -75.6972},
+			"2": {CenterId: "2", Latitude: 45.3500, Longitude: -75.7900},
+		},
+		Matrix: map[string]map[string]time.Duration{"1": {"3": 25 * time.Minute}},
+	}
+
+	d, ok := travel.Between("1", "2")
+	if !ok || d < 20*time.Minute || d > 25*time.Minute {
+		t.Errorf("Expected about 22m at 30 km/h but got %v", d)
+	}
+
+	d, ok = travel.Between("3", "1")
+	if !ok || d != 25*time.Minute {
+		t.Errorf("Expected 25m from the matrix but got %v", d)
+	}
+
+	d, ok = travel.Between("2", "2")
+	if !ok || d != 0 {
+		t.Errorf("Expected no travel within a center but got %v", d)
+	}
+
+	_, ok = travel.Between("2", "4")
+	if ok {
+		t.Errorf("Expected an unknown travel time without a location")
+	}
+}
+
+func TestFindConflictsTravel(t *testing.T) {
+	plan := &Plan{Centers: []*CenterWeek{
+		{CenterId: "1", CenterName: "Pool", Events: []*Activity{
+			{Id: 1, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:00 AM - 9:45 AM", Persons: []string{"ana"}},
+			{Id: 2, Name: "Art", DayOfWeek: "Sat", TimeRange: "1:00 PM - 2:00 PM", Persons: []string{"ana"}},
+		}},
+		{CenterId: "3", CenterName: "Arena", Events: []*Activity{
+			{Id: 3, Name: "Skate", DayOfWeek: "Sat", TimeRange: "10:00 AM - 10:45 AM", Persons: []string{"ana"}},
+		}},
+	}}
+	travel := &TravelTimes{Matrix: map[string]map[string]time.Duration{"1": {"3": 25 * time.Minute}}}
+
+	conflicts, err := FindConflicts(plan, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts without travel times but got %v", conflicts)
+	}
+
+	conflicts, err = FindConflicts(plan, nil, travel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict but got %v", len(conflicts))
+	}
+	c := conflicts[0]
+	if c.First.Id != 1 || c.Second.Id != 3 || c.Travel == nil || c.Travel.GapMinutes != 15 || c.Travel.TravelMinutes != 25 {
+		t.Errorf("Expected swim and skate 15m apart with 25m of travel but got %+v", c)
+	}
+}
+
+func TestParseDistance(t *testing.T) {
+	cases := map[string]float64{"5km": 5, "5 km": 5, "800m": 0.8, "2": 2, "1mi": 1.609344}
+	for s, expected := range cases {
+		d, err := ParseDistance(s)
+		if err != nil {
+			t.Errorf("Expected %v to parse but got %v", s, err)
+		}
+		if math.Abs(d-expected) > 1e-9 {
+			t.Errorf("Expected %v km for %v but got %v", expected, s, d)
+		}
+	}
+
+	_, err := ParseDistance("far")
+	if err == nil {
+		t.Errorf("Expected an error for an unexpected distance")
+	}
+}
+
+func TestCentersWithin(t *testing.T) {
+	centers := Criteria{{Id: "1", Description: "Far"}, {Id: "2", Description: "Near"}, {Id: "3", Description: "Unknown"}, {Id: "4", Description: "Nearer"}}
+	distances := map[string]float64{"1": 12, "2": 3.5, "4": 1.2}
+
+	within := CentersWithin(centers, distances, 5)
+	if len(within) != 2 || within[0].Id != "4" || within[1].Id != "2" {
+		t.Errorf("Expected Nearer and Near but got %v", within)
+	}
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Problem is something wrong with a plan. Path locates it in the JSON of the
+// plan, like centers[0].events[2].time_range.
+type Problem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Fixable problems are repaired by Repair without losing information.
+	Fixable bool `json:"fixable"`
+}
+
+// Problems checks the plan for missing IDs, unparseable time ranges, unknown
+// weekdays, duplicate activities, activities without persons, invalid
+// selections and centers without activities.
+func (p *Plan) Problems() []*Problem {
+	problems := []*Problem{}
+	add := func(path string, fixable bool, format string, a ...any) {
+		problems = append(problems, &Problem{Path: path, Message: fmt.Sprintf(format, a...), Fixable: fixable})
+	}
+
+	centerPaths := map[string]string{}
+	activityPaths := map[int]string{}
+	activityCenters := map[int]string{}
+	for ci, cw := range p.Centers {
+		centerPath := fmt.Sprintf("centers[%d]", ci)
+		if cw.CenterId == "" {
+			add(centerPath+".center_id", false, "center has no ID")
+		} else if first, ok := centerPaths[cw.CenterId]; ok {
+			add(centerPath, true, "center %v is also at %v", cw.CenterId, first)
+		} else {
+			centerPaths[cw.CenterId] = centerPath
+		}
+
+		if len(cw.Events) == 0 {
+			add(centerPath, true, "center %v has no activities", cw.CenterId)
+		}
+
+		for ei, e := range cw.Events {
+			path := fmt.Sprintf("%v.events[%d]", centerPath, ei)
+			if e.Id == 0 {
+				add(path+".id", false, "activity has no ID")
+			} else if first, ok := activityPaths[e.Id]; ok {
+				sameCenter := activityCenters[e.Id] == cw.CenterId
+				add(path, sameCenter, "activity %v is also at %v", e.Id, first)
+			} else {
+				activityPaths[e.Id] = path
+				activityCenters[e.Id] = cw.CenterId
+			}
+
+			_, err := e.StartTime()
+			if err == nil {
+				_, err = e.EndTime()
+			}
+			if err != nil {
+				add(path+".time_range", false, "unparseable time range %q", e.TimeRange)
+			}
+
+			_, err = e.Weekday()
+			if err != nil {
+				_, parseErr := ParseWeekday(e.DayOfWeek)
+				add(path+".days_of_week", parseErr == nil, "unknown weekday %q", e.DayOfWeek)
+			}
+
+			if len(e.Persons) == 0 {
+				// removing it would lose the activity, attaching it is up to the user
+				add(path+".persons", false, "activity %v isn't attached to any person", e.Id)
+			}
+
+			for _, person := range sortedKeys(e.Selections) {
+				selection := e.Selections[person]
+				if _, err := ParseSelection(string(selection)); err != nil {
+					add(path+".selections."+person, true, "unexpected selection %q for %v", selection, person)
+				} else if !e.HasPerson(person) {
+					add(path+".selections."+person, true, "selection for %v, who isn't attached to the activity", person)
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// Repair fixes the fixable problems of the plan and returns them: centers and
+// activities that appear twice in a center are merged, weekdays are
+// abbreviated, invalid selections are dropped and centers without activities
+// are removed. Activities without persons are kept.
+func (p *Plan) Repair() []*Problem {
+	fixed := []*Problem{}
+	for _, problem := range p.Problems() {
+		if problem.Fixable {
+			fixed = append(fixed, problem)
+		}
+	}
+
+	centers := []*CenterWeek{}
+	byId := map[string]*CenterWeek{}
+	for _, cw := range p.Centers {
+		if first, ok := byId[cw.CenterId]; ok && cw.CenterId != "" {
+			first.Events = append(first.Events, cw.Events...)
+			continue
+		}
+		byId[cw.CenterId] = cw
+		centers = append(centers, cw)
+	}
+
+	p.Centers = []*CenterWeek{}
+	for _, cw := range centers {
+		events := []*Activity{}
+		for _, e := range cw.Events {
+			if _, err := e.Weekday(); err != nil {
+				day, err := ParseWeekday(e.DayOfWeek)
+				if err == nil {
+					e.DayOfWeek = day.String()[:3]
+				}
+			}
+
+			for person, selection := range e.Selections {
+				if _, err := ParseSelection(string(selection)); err != nil || !e.HasPerson(person) {
+					delete(e.Selections, person)
+				}
+			}
+
+			events = append(events, e)
+		}
+
+		cw.Events = mergeActivities(events)
+		if len(cw.Events) > 0 {
+			p.Centers = append(p.Centers, cw)
+		}
+	}
+
+	return fixed
+}
+
+func sortedKeys(selections map[string]Selection) []string {
+	keys := []string{}
+	for k := range selections {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (p *Problem) String() string {
+	var b strings.Builder
+	if p.Path != "" {
+		fmt.Fprintf(&b, "%v: ", p.Path)
+	}
+	b.WriteString(p.Message)
+	if p.Fixable {
+		b.WriteString(" (fixable)")
+	}
+	return b.String()
+}
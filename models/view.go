@@ -2,12 +2,14 @@ package models
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
 
 type ViewEvent struct {
 	Activity *Activity
+	Persons  []string
 
 	StartTime string
 	Duration  int
@@ -15,13 +17,21 @@ type ViewEvent struct {
 	Span      int
 	BgColor   string
 
-	prevEvent *ViewEvent
+	// Conflicts describe the activities this one conflicts with.
+	Conflicts []string `json:",omitempty"`
 }
 
 func (ve *ViewEvent) String() string {
 	return fmt.Sprint(ve.Activity.Id)
 }
 
+// UnavailableTime is a block of the week view during which nobody is
+// available, starting at StartTime and spanning Span rows.
+type UnavailableTime struct {
+	StartTime string
+	Span      int
+}
+
 type WeekDay struct {
 	Name      string
 	ShortName string
@@ -37,6 +47,9 @@ type WeekdayView struct {
 	Span        int
 	GridColumns []int
 	Events      []*ViewEvent
+
+	// Unavailable are the times shaded as unavailable.
+	Unavailable []*UnavailableTime `json:",omitempty"`
 }
 
 type CenterView struct {
@@ -54,6 +67,79 @@ type View struct {
 	Days []WeekDay
 
 	Times []Time
+
+	// Fill is the time to fill chart, shown when set.
+	Fill []*FillBar `json:",omitempty"`
+
+	// Cost is the cost of the selected activities, shown when set.
+	Cost *CostReport `json:",omitempty"`
+}
+
+// MarkConflicts adds the conflicts to the events of both of their
+// activities.
+func (v *View) MarkConflicts(conflicts []*Conflict) {
+	describe := func(c *Conflict, other *Activity) string {
+		s := fmt.Sprintf("%v: %v %v", c.Person, other.Name, other.TimeRange)
+		if c.Travel != nil {
+			s += fmt.Sprintf(", %v travel with %v between", FormatDuration(c.Travel.Travel), FormatDuration(c.Travel.Gap))
+		}
+		return s
+	}
+
+	for _, cv := range v.Centers {
+		for _, wv := range cv.Weekdays {
+			for _, e := range wv.Events {
+				for _, c := range conflicts {
+					if c.First.Id == e.Activity.Id {
+						e.Conflicts = append(e.Conflicts, describe(c, c.Second))
+					}
+					if c.Second.Id == e.Activity.Id {
+						e.Conflicts = append(e.Conflicts, describe(c, c.First))
+					}
+				}
+			}
+		}
+	}
+}
+
+// ShadeUnavailable marks the times of the view at which none of the persons,
+// or no driver, is available.
+func (v *View) ShadeUnavailable(persons []Availability, drivers Availability) {
+	available := func(day time.Weekday, start TimeOfDay, end TimeOfDay) bool {
+		if !drivers.contains(day, start, end) {
+			return false
+		}
+		if len(persons) == 0 {
+			return true
+		}
+		return slices.ContainsFunc(persons, func(av Availability) bool { return av.contains(day, start, end) })
+	}
+
+	for _, cv := range v.Centers {
+		for i, wv := range cv.Weekdays {
+			wv.Unavailable = nil
+			var block *UnavailableTime
+			for _, t := range v.Times {
+				// each row of the view is 15 minutes, coded like 0915
+				var start TimeOfDay
+				_, err := fmt.Sscanf(t.Code, "%02d%02d", &start.Hour, &start.Minute)
+				if err != nil {
+					continue
+				}
+				end := TimeOfDay{start.Hour + (start.Minute+15)/60, (start.Minute + 15) % 60}
+
+				if available(time.Weekday(i), start, end) {
+					block = nil
+					continue
+				}
+				if block == nil {
+					block = &UnavailableTime{StartTime: t.Code}
+					wv.Unavailable = append(wv.Unavailable, block)
+				}
+				block.Span += 1
+			}
+		}
+	}
 }
 
 func weekdays() []WeekDay {
@@ -82,85 +168,145 @@ func times() []Time {
 	return list
 }
 
-/*
+func NewViewEvent(a *Activity, colourMap map[string]string, ordering Ordering) (*ViewEvent, error) {
+	st, err := a.StartTime()
+	if err != nil {
+		return nil, err
+	}
 
-A
-AB
- BCD
-  CD
+	et, err := a.EndTime()
+	if err != nil {
+		return nil, err
+	}
 
-*/
+	persons := slices.Clone(a.Persons)
+	ordering.sortPersons(persons)
 
-func NewViewEvent(a *Activity, prevEvent *ViewEvent, colourMap map[string]string) (*ViewEvent, error) {
-	offset := 0
-	if prevEvent != nil {
-		var overlaps bool
-		var err error
-		for {
-			overlaps, err = prevEvent.Activity.Overlaps(a)
+	return &ViewEvent{
+		Activity:  a,
+		Persons:   persons,
+		StartTime: st.Time24H(),
+		Duration:  et.Difference(&st),
+		Offset:    0,
+		Span:      1,
+		BgColor:   colourMap[ordering.colourKey(a)],
+	}, nil
+}
+
+// layoutEvents assigns each event of a day, sorted by start time, to a
+// column. Events are split into clusters of transitively overlapping events
+// and each cluster is partitioned greedily, giving every event the leftmost
+// column that is free when it starts. This uses as few columns as the
+// largest number of simultaneous events. Each event then expands to the right
+// over the columns that no overlapping event occupies. Returns the number of
+// columns needed by the day.
+func layoutEvents(events []*ViewEvent) (int, error) {
+	columns := 1
+	clusters := [][]*ViewEvent{}
+	for start := 0; start < len(events); {
+		clusterEnd, err := events[start].Activity.EndTime()
+		if err != nil {
+			return 0, err
+		}
+
+		end := start + 1
+		for ; end < len(events); end++ {
+			st, err := events[end].Activity.StartTime()
 			if err != nil {
-				return nil, err
+				return 0, err
 			}
-
-			if overlaps || prevEvent.Offset == 0 || prevEvent.prevEvent == nil {
+			if !st.LessThan(&clusterEnd) {
 				break
-			} else {
-				prevEvent = prevEvent.prevEvent
 			}
-		}
 
-		if overlaps {
-			overlapMap := map[int]*ViewEvent{
-				prevEvent.Offset: prevEvent,
+			et, err := events[end].Activity.EndTime()
+			if err != nil {
+				return 0, err
 			}
-			offsetEvent := prevEvent
-			for offsetEvent.prevEvent != nil {
-				prevOverlaps, err := offsetEvent.prevEvent.Activity.Overlaps(a)
-				if err != nil {
-					return nil, err
-				}
+			if clusterEnd.LessThan(&et) {
+				clusterEnd = et
+			}
+		}
 
-				if prevOverlaps {
-					overlapMap[offsetEvent.prevEvent.Offset] = offsetEvent.prevEvent
-				}
+		cluster := events[start:end]
+		clusterColumns, err := partitionCluster(cluster)
+		if err != nil {
+			return 0, err
+		}
+		columns = max(columns, clusterColumns)
+		clusters = append(clusters, cluster)
 
-				offsetEvent = offsetEvent.prevEvent
-			}
+		start = end
+	}
+
+	for _, cluster := range clusters {
+		err := expandCluster(cluster, columns)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return columns, nil
+}
 
-			var i int
-			ok := true
-			for i = 0; ok; i++ {
-				_, ok = overlapMap[i]
+func partitionCluster(cluster []*ViewEvent) (int, error) {
+	// columnEnds holds the end time of the last event placed in each column
+	columnEnds := []TimeOfDay{}
+	for _, e := range cluster {
+		st, err := e.Activity.StartTime()
+		if err != nil {
+			return 0, err
+		}
+
+		et, err := e.Activity.EndTime()
+		if err != nil {
+			return 0, err
+		}
+
+		e.Offset = len(columnEnds)
+		for i := range columnEnds {
+			if !st.LessThan(&columnEnds[i]) {
+				e.Offset = i
+				break
 			}
+		}
 
-			offset = i - 1
+		if e.Offset == len(columnEnds) {
+			columnEnds = append(columnEnds, et)
 		} else {
-			prevEvent = nil
+			columnEnds[e.Offset] = et
 		}
 	}
 
-	st, err := a.StartTime()
-	if err != nil {
-		return nil, err
-	}
+	return len(columnEnds), nil
+}
 
-	et, err := a.EndTime()
-	if err != nil {
-		return nil, err
+func expandCluster(cluster []*ViewEvent, columns int) error {
+	for _, e := range cluster {
+		// occupied is the first column to the right taken by an overlapping
+		// event
+		occupied := columns
+		for _, other := range cluster {
+			if other == e || other.Offset <= e.Offset || other.Offset >= occupied {
+				continue
+			}
+
+			overlaps, err := e.Activity.Overlaps(other.Activity)
+			if err != nil {
+				return err
+			}
+			if overlaps {
+				occupied = other.Offset
+			}
+		}
+
+		e.Span = occupied - e.Offset
 	}
 
-	return &ViewEvent{
-		Activity:  a,
-		StartTime: st.Time24H(),
-		Duration:  et.Difference(&st),
-		Offset:    offset,
-		Span:      1,
-		BgColor:   colourMap[a.Name],
-		prevEvent: prevEvent,
-	}, nil
+	return nil
 }
 
-func NewCenterView(cw *CenterWeek, days []WeekDay) (*CenterView, error) {
+func NewCenterView(cw *CenterWeek, days []WeekDay, ordering Ordering) (*CenterView, error) {
 	colours := []string{
 		"rgb(234, 153, 153)",
 		"rgb(249, 203, 156)",
@@ -169,15 +315,19 @@ func NewCenterView(cw *CenterWeek, days []WeekDay) (*CenterView, error) {
 		"rgb(162, 196, 201)",
 		"rgb(164, 194, 244)",
 	}
+	// the same activity may have been loaded for several persons
+	events := mergeActivities(slices.Clone(cw.Events))
+
 	colourMap := map[string]string{}
 	colorIndex := 0
-	for _, e := range cw.Events {
-		_, ok := colourMap[e.Name]
+	for _, e := range events {
+		key := ordering.colourKey(e)
+		_, ok := colourMap[key]
 		if !ok {
 			if colorIndex >= len(colours) {
-				colourMap[e.Name] = "white"
+				colourMap[key] = "white"
 			} else {
-				colourMap[e.Name] = colours[colorIndex]
+				colourMap[key] = colours[colorIndex]
 			}
 			colorIndex++
 		}
@@ -189,7 +339,7 @@ func NewCenterView(cw *CenterWeek, days []WeekDay) (*CenterView, error) {
 		Weekdays:   []*WeekdayView{},
 	}
 
-	dailyActivities, err := eventsByWeekday(cw.Events)
+	dailyActivities, err := eventsByWeekday(events, ordering)
 	if err != nil {
 		return nil, err
 	}
@@ -200,34 +350,20 @@ func NewCenterView(cw *CenterWeek, days []WeekDay) (*CenterView, error) {
 		sortedActivities := dailyActivities[time.Weekday(i)]
 
 		viewEvents := []*ViewEvent{}
-		var prevEvent *ViewEvent
-		maxOffset := 0
 		for _, a := range sortedActivities {
-			e, err := NewViewEvent(a, prevEvent, colourMap)
+			e, err := NewViewEvent(a, colourMap, ordering)
 			if err != nil {
 				return nil, err
 			}
 
 			viewEvents = append(viewEvents, e)
-			if e.Offset > maxOffset {
-				maxOffset = e.Offset
-			}
-			prevEvent = e
 		}
 
-		for j, ve := range viewEvents {
-			if j+1 < len(viewEvents) {
-				nextEvent := viewEvents[j+1]
-
-				if ve.Offset == 0 && ve.prevEvent == nil && nextEvent.Offset == 0 {
-					ve.Span = maxOffset + 1
-				}
-			} else if ve.Offset == 0 && ve.prevEvent == nil {
-				ve.Span = maxOffset + 1
-			}
+		span, err := layoutEvents(viewEvents)
+		if err != nil {
+			return nil, err
 		}
 
-		span := maxOffset + 1
 		columns := make([]int, span)
 		for i := range span {
 			columns[i] = gc + i
@@ -293,15 +429,22 @@ func lcm(arr []int) int {
 	return ans
 }
 
-func NewView(plan *CenterPlan) (*View, error) {
+func NewView(plan *CenterPlan, ordering Ordering) (*View, error) {
+	err := ordering.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	v := View{
 		Centers: []*CenterView{},
 		Days:    weekdays(),
 		Times:   times(),
 	}
 
-	for _, p := range plan.Plans {
-		cv, err := NewCenterView(p, v.Days)
+	centers := slices.Clone(plan.Plans)
+	ordering.sortCenters(centers)
+	for _, p := range centers {
+		cv, err := NewCenterView(p, v.Days, ordering)
 		if err != nil {
 			return nil, err
 		}
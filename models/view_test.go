@@ -1,6 +1,14 @@
 package models
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestLcm(t *testing.T) {
 	a := lcm([]int{1, 2, 3})
@@ -19,7 +27,240 @@ func TestLcm(t *testing.T) {
 func TestGridColumns(t *testing.T) {
 	a := gridColumns([]int{1, 2, 3, 1, 1, 1, 1})
 
-	if a != "1fr 6fr 3fr 3fr 2fr 2fr 2fr 6fr 6fr 6fr 6fr" {
-		t.Errorf("Expected '1fr 6fr 3fr 3fr 2fr 2fr 2fr 6fr 6fr 6fr 6fr' but got %s", a)
+	if a != "50px 6fr 3fr 3fr 2fr 2fr 2fr 6fr 6fr 6fr 6fr" {
+		t.Errorf("Expected '50px 6fr 3fr 3fr 2fr 2fr 2fr 6fr 6fr 6fr 6fr' but got %s", a)
+	}
+}
+
+type layoutCase struct {
+	name    string
+	ranges  []string
+	columns int
+	// offsets and spans of each event, in the order of ranges
+	offsets []int
+	spans   []int
+}
+
+func TestLayoutEvents(t *testing.T) {
+	cases := []layoutCase{
+		{
+			name:    "single",
+			ranges:  []string{"9:00 AM - 10:00 AM"},
+			columns: 1,
+			offsets: []int{0},
+			spans:   []int{1},
+		},
+		{
+			name:    "back to back",
+			ranges:  []string{"9:00 AM - 10:00 AM", "10:00 AM - 11:00 AM"},
+			columns: 1,
+			offsets: []int{0, 0},
+			spans:   []int{1, 1},
+		},
+		{
+			name:    "identical",
+			ranges:  []string{"9:00 AM - 10:00 AM", "9:00 AM - 10:00 AM", "9:00 AM - 10:00 AM"},
+			columns: 3,
+			offsets: []int{0, 1, 2},
+			spans:   []int{1, 1, 1},
+		},
+		{
+			// A
+			// AB
+			//  BCD
+			//   CD
+			name: "staircase",
+			ranges: []string{
+				"9:00 AM - 10:00 AM",
+				"9:30 AM - 10:30 AM",
+				"10:00 AM - 11:00 AM",
+				"10:00 AM - 11:00 AM",
+			},
+			columns: 3,
+			offsets: []int{0, 1, 0, 2},
+			spans:   []int{1, 1, 1, 1},
+		},
+		{
+			// a later event reuses the leftmost column that has become
+			// free rather than opening a new one
+			name: "reuse column",
+			ranges: []string{
+				"9:00 AM - 9:30 AM",
+				"9:00 AM - 11:00 AM",
+				"9:00 AM - 9:30 AM",
+				"9:30 AM - 10:00 AM",
+			},
+			columns: 3,
+			offsets: []int{0, 1, 2, 0},
+			spans:   []int{1, 1, 1, 1},
+		},
+		{
+			// the gap left in the last column is used by an event that
+			// doesn't overlap anything in it
+			name: "non-adjacent free column",
+			ranges: []string{
+				"9:00 AM - 12:00 PM",
+				"9:00 AM - 10:00 AM",
+				"9:00 AM - 10:00 AM",
+				"10:00 AM - 11:00 AM",
+			},
+			columns: 3,
+			offsets: []int{0, 1, 2, 1},
+			spans:   []int{1, 1, 1, 2},
+		},
+		{
+			// separate clusters are laid out independently and the narrower
+			// one expands to the width of the day
+			name: "separate clusters",
+			ranges: []string{
+				"9:00 AM - 10:00 AM",
+				"9:00 AM - 10:00 AM",
+				"9:00 AM - 10:00 AM",
+				"1:00 PM - 2:00 PM",
+				"1:30 PM - 2:30 PM",
+			},
+			columns: 3,
+			offsets: []int{0, 1, 2, 0, 1},
+			spans:   []int{1, 1, 1, 1, 2},
+		},
+		{
+			// a long event keeps the cluster open across events that don't
+			// overlap each other
+			name: "chained cluster",
+			ranges: []string{
+				"9:00 AM - 1:00 PM",
+				"9:00 AM - 10:00 AM",
+				"11:00 AM - 12:00 PM",
+				"11:30 AM - 12:30 PM",
+			},
+			columns: 3,
+			offsets: []int{0, 1, 1, 2},
+			spans:   []int{1, 2, 1, 1},
+		},
+	}
+
+	for _, c := range cases {
+		events := []*ViewEvent{}
+		for i, r := range c.ranges {
+			e, err := NewViewEvent(&Activity{Id: i, Name: fmt.Sprint(i), TimeRange: r, DayOfWeek: "Mon"}, map[string]string{}, Ordering{})
+			if err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			events = append(events, e)
+		}
+
+		columns, err := layoutEvents(events)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if columns != c.columns {
+			t.Errorf("%s: expected %d columns but got %d", c.name, c.columns, columns)
+		}
+
+		for i, e := range events {
+			if e.Offset != c.offsets[i] || e.Span != c.spans[i] {
+				t.Errorf("%s: expected event %d at offset %d with span %d but got offset %d with span %d",
+					c.name, i, c.offsets[i], c.spans[i], e.Offset, e.Span)
+			}
+		}
+
+		for i, a := range events {
+			for _, b := range events[i+1:] {
+				overlaps, _ := a.Activity.Overlaps(b.Activity)
+				columnsOverlap := a.Offset < b.Offset+b.Span && b.Offset < a.Offset+a.Span
+				if overlaps && columnsOverlap {
+					t.Errorf("%s: events %d and %d overlap", c.name, a.Activity.Id, b.Activity.Id)
+				}
+			}
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestNewViewGolden(t *testing.T) {
+	planBytes, err := os.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plan Plan
+	err = json.Unmarshal(planBytes, &plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orderings := map[string]Ordering{
+		"view.golden.json":      {},
+		"view.name.golden.json": {Centers: OrderName, Persons: OrderName, Activities: OrderName},
+		"view.id.golden.json":   {Centers: OrderId, Persons: OrderName, Activities: OrderId},
+		"view.user.golden.json": {UserCenters: []string{"Plant Rec"}, UserPersons: []string{"Bob"}},
+	}
+
+	for golden, ordering := range orderings {
+		view, err := NewView(plan.CenterPlan(), ordering)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		goldenPath := filepath.Join("testdata", golden)
+		if *update {
+			err = os.WriteFile(goldenPath, actual, 0664)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		expected, err := os.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("View does not match %v, run the tests with -update to see the differences", goldenPath)
+		}
+	}
+}
+
+func TestMarkConflicts(t *testing.T) {
+	plan := &Plan{Centers: []*CenterWeek{
+		{CenterId: "1", CenterName: "Pool", Events: []*Activity{
+			{Id: 1, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:00 AM - 10:00 AM", Persons: []string{"ana"}, Selections: map[string]Selection{"ana": Selected}},
+			{Id: 2, Name: "Dive", DayOfWeek: "Sat", TimeRange: "9:30 AM - 10:30 AM", Persons: []string{"ana"}, Selections: map[string]Selection{"ana": Shortlisted}},
+			{Id: 3, Name: "Swim", DayOfWeek: "Sat", TimeRange: "9:00 AM - 10:00 AM", Persons: []string{"ana"}},
+		}},
+	}}
+
+	view, err := NewView(plan.CenterPlan(), Ordering{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflicts, err := FindConflicts(plan, []Selection{Selected, Shortlisted}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view.MarkConflicts(conflicts)
+
+	// the unselected swim overlaps both but isn't marked
+	expected := map[int]int{1: 1, 2: 1, 3: 0}
+	events := 0
+	for _, cv := range view.Centers {
+		for _, wv := range cv.Weekdays {
+			for _, e := range wv.Events {
+				events++
+				if len(e.Conflicts) != expected[e.Activity.Id] {
+					t.Errorf("Expected %d conflicts for activity %d but got %v", expected[e.Activity.Id], e.Activity.Id, e.Conflicts)
+				}
+			}
+		}
+	}
+	if events != len(expected) {
+		t.Errorf("Expected %d events but got %d", len(expected), events)
 	}
 }
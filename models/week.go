@@ -2,7 +2,6 @@ package models
 
 import (
 	"fmt"
-	"sort"
 	"time"
 )
 
@@ -37,6 +36,16 @@ func (t *TimeOfDay) Difference(tod *TimeOfDay) int {
 	return diff
 }
 
+// Minutes returns the number of minutes since midnight.
+func (t *TimeOfDay) Minutes() int {
+	return t.Hour*60 + t.Minute
+}
+
+// String formats the time as ActiveNet does, "3:04 PM".
+func (t TimeOfDay) String() string {
+	return time.Date(0, 1, 1, t.Hour, t.Minute, 0, 0, time.UTC).Format("3:04 PM")
+}
+
 func (t *TimeOfDay) LessThan(tod *TimeOfDay) bool {
 	return t.Hour < tod.Hour || (t.Hour == tod.Hour && t.Minute < tod.Minute)
 }
@@ -55,7 +64,7 @@ func (a ByStartTime) Less(i, j int) bool {
 	return iMinutes < jMinutes
 }
 
-func eventsByWeekday(events []*Activity) (map[time.Weekday][]*Activity, error) {
+func eventsByWeekday(events []*Activity, ordering Ordering) (map[time.Weekday][]*Activity, error) {
 	result := map[time.Weekday][]*Activity{}
 	for i := time.Sunday; i <= time.Saturday; i++ {
 		result[i] = []*Activity{}
@@ -71,7 +80,7 @@ func eventsByWeekday(events []*Activity) (map[time.Weekday][]*Activity, error) {
 	}
 
 	for i := time.Sunday; i <= time.Saturday; i++ {
-		sort.Sort(ByStartTime(result[i]))
+		ordering.sortActivities(result[i])
 	}
 
 	return result, nil